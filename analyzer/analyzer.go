@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// Analyzer 可复用的天气分析器，每次分析时传入天气数据
+// 配置只读，同一 Analyzer 可在多个 goroutine 中并发使用
+type Analyzer struct {
+	cfg *AnalyzerConfig
+}
+
+// NewAnalyzer 按配置创建天气分析器，cfg 为 nil 时使用默认配置
+// 分析器保存配置的副本，此后再对 cfg 应用选项不影响分析器
+func NewAnalyzer(cfg *AnalyzerConfig) *Analyzer {
+	if cfg == nil {
+		return &Analyzer{cfg: defaultAnalyzerConfig()}
+	}
+	return &Analyzer{cfg: cfg.clone()}
+}
+
+// Config 分析器配置的副本，修改副本不影响分析器，可通过 With 派生新配置
+func (a *Analyzer) Config() *AnalyzerConfig {
+	return a.cfg.clone()
+}
+
+// Analyze 分析天气数据并返回分析结果，ctx 取消时返回 ErrCanceled 错误
+func (a *Analyzer) Analyze(ctx context.Context, conditions []WeatherCondition) (*WeatherAnalysisResult, error) {
+	if err := checkConditions(conditions); err != nil {
+		return nil, err
+	}
+	data, err := a.prepare(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return a.analyze(data, "今日"), nil
+}
+
+// checkContext 检查分析是否已取消
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &utils.WeatherError{
+			Code:    utils.ErrCanceled,
+			Message: "天气分析已取消",
+			Err:     err,
+		}
+	}
+	return nil
+}
@@ -1,7 +1,13 @@
 package analyzer
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
 	"testing"
+
+	"github.com/louismax/weather_analyzer/utils"
 )
 
 func TestWeatherAnalyzer(t *testing.T) {
@@ -72,7 +78,7 @@ func TestWeatherAnalyzer(t *testing.T) {
 	// 验证分析结果
 	expected := &WeatherAnalysisResult{
 		DominantCondition:  "特大暴雨",
-		OtherConditions:    []string{"大暴雨", "极端降雨", "强沙尘暴"},
+		OtherConditions:    []RankedCondition{{Condition: "大暴雨"}, {Condition: "极端降雨"}, {Condition: "强沙尘暴"}},
 		AverageTemperature: 13.5,
 		TotalPrecipitation: 500.2,
 		MaxPrecipitation:   100.0,
@@ -125,3 +131,62 @@ func TestWeatherAnalyzer(t *testing.T) {
 	}
 
 }
+
+func TestWeatherAnalyzerLogger(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0, WindSpeed: 5.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	analyzer.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	analyzer.SetCustomWeights(map[string]float64{"晴": 0.4, "自定义": 0.5})
+
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "condition=晴") || !strings.Contains(out, "new_value=0.4") {
+		t.Errorf("覆盖权重日志错误: %s", out)
+	}
+	if !strings.Contains(out, "level=INFO") || !strings.Contains(out, "condition=自定义") {
+		t.Errorf("新增权重日志错误: %s", out)
+	}
+
+	// 静默日志不应输出任何内容
+	buf.Reset()
+	analyzer.SetLogger(utils.NopLogger)
+	analyzer.SetCustomWeights(map[string]float64{"晴": 0.3})
+	if buf.Len() != 0 {
+		t.Errorf("静默日志仍有输出: %s", buf.String())
+	}
+}
+
+func TestNewWeatherAnalyzerError(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0},
+		{Time: "2024-01-01 01:00", Temperature: 120.0, Condition: "晴", Humidity: 60.0},
+	}
+	_, err := NewWeatherAnalyzer(conditions)
+	if err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if !errors.Is(err, utils.ErrInvalidInput) || !errors.Is(err, utils.ErrInvalidTemperature) {
+		t.Errorf("错误码匹配失败: %v", err)
+	}
+	if errors.Is(err, utils.ErrInvalidHumidity) {
+		t.Errorf("错误码不应匹配湿度错误: %v", err)
+	}
+	var we *utils.WeatherError
+	if !errors.As(err, &we) {
+		t.Fatalf("错误类型错误: %T", err)
+	}
+	if we.Index != 2 || we.Field != "Temperature" || we.Value != 120.0 {
+		t.Errorf("错误上下文错误，实际 Index=%d Field=%s Value=%v", we.Index, we.Field, we.Value)
+	}
+
+	_, err = NewWeatherAnalyzer([]WeatherCondition{})
+	if utils.CodeOf(err) != utils.ErrEmptyData {
+		t.Errorf("错误码错误，期望 %s，实际 %s", utils.ErrEmptyData, utils.CodeOf(err))
+	}
+}
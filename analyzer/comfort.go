@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// ThermalComfort 由温度、湿度、风速推算的体感指标（摄氏度）
+type ThermalComfort struct {
+	// DewPoint 露点，有观测露点时取观测值
+	DewPoint float64
+	// HeatIndex 炎热指数
+	HeatIndex float64
+	// WindChill 风寒温度
+	WindChill float64
+	// ApparentTemperature Steadman 体感温度
+	ApparentTemperature float64
+}
+
+// ComfortThresholds 按体感指标推导“热”“冷”天气的阈值，推导结果见分析结果的 ComfortConditions
+type ComfortThresholds struct {
+	// Enabled 是否推导“热”“冷”天气
+	Enabled bool
+	// HotHeatIndex 炎热指数不低于该值时视为“热”（摄氏度）
+	HotHeatIndex float64
+	// ColdWindChill 风寒温度不高于该值时视为“冷”（摄氏度）
+	ColdWindChill float64
+	// Scored 推导的“热”“冷”是否参与主导天气判断，默认不参与
+	// 参与时按权重表中“热”“冷”的权重与记录原有的天气状况并列计时评分，如炎热晴天同时计入晴与热
+	Scored bool
+}
+
+// DefaultComfortThresholds 默认体感阈值：炎热指数≥35°C为热，风寒温度≤-10°C为冷
+func DefaultComfortThresholds() ComfortThresholds {
+	return ComfortThresholds{
+		Enabled:       true,
+		HotHeatIndex:  35,
+		ColdWindChill: -10,
+	}
+}
+
+// DewPoint 按Magnus公式由温度(摄氏度)和相对湿度(%)推算露点
+func DewPoint(t, rh float64) float64 {
+	const a, b = 17.27, 237.7
+	gamma := a*t/(b+t) + math.Log(math.Max(rh, 1)/100)
+	return b * gamma / (a - gamma)
+}
+
+// HeatIndex 按美国国家气象局(NWS)算法由温度(摄氏度)和相对湿度(%)计算炎热指数
+// 温度较低时采用 Steadman 简化公式，结果不高于约27°C时与温度接近
+func HeatIndex(t, rh float64) float64 {
+	f := t*9/5 + 32
+	hi := 0.5 * (f + 61 + (f-68)*1.2 + rh*0.094)
+	if (hi+f)/2 < 80 {
+		return (hi - 32) * 5 / 9
+	}
+
+	hi = -42.379 + 2.04901523*f + 10.14333127*rh - 0.22475541*f*rh -
+		0.00683783*f*f - 0.05481717*rh*rh + 0.00122874*f*f*rh +
+		0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+	switch {
+	case rh < 13 && f >= 80 && f <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+	case rh > 85 && f >= 80 && f <= 87:
+		hi += (rh - 85) / 10 * (87 - f) / 5
+	}
+	return (hi - 32) * 5 / 9
+}
+
+// WindChill 按加拿大与美国联合风寒指数公式由温度(摄氏度)和风速(米/秒)计算风寒温度
+// 公式适用于温度不高于10°C且风速高于4.8公里/小时，超出范围时返回原温度
+func WindChill(t, windSpeed float64) float64 {
+	v := windSpeed * 3.6
+	if t > 10 || v <= 4.8 {
+		return t
+	}
+	p := math.Pow(v, 0.16)
+	return 13.12 + 0.6215*t - 11.37*p + 0.3965*t*p
+}
+
+// ApparentTemperature 按 Steadman 公式(澳大利亚气象局采用的非辐射版本)由温度(摄氏度)、相对湿度(%)和风速(米/秒)计算体感温度
+func ApparentTemperature(t, rh, windSpeed float64) float64 {
+	e := rh / 100 * 6.105 * math.Exp(17.27*t/(237.7+t))
+	return t + 0.33*e - 0.70*windSpeed - 4.00
+}
+
+// thermalComfort 计算单条记录的体感指标
+func thermalComfort(c WeatherCondition) ThermalComfort {
+	comfort := ThermalComfort{
+		DewPoint:            DewPoint(c.Temperature, c.Humidity),
+		HeatIndex:           HeatIndex(c.Temperature, c.Humidity),
+		WindChill:           WindChill(c.Temperature, c.WindSpeed),
+		ApparentTemperature: ApparentTemperature(c.Temperature, c.Humidity, c.WindSpeed),
+	}
+	if c.DewPoint != nil {
+		comfort.DewPoint = *c.DewPoint
+	}
+	return comfort
+}
+
+// derivedCondition 按体感阈值推导的天气状况，“热”“冷”以外返回空字符串
+func (th ComfortThresholds) derivedCondition(comfort ThermalComfort) string {
+	if !th.Enabled {
+		return ""
+	}
+	switch {
+	case comfort.HeatIndex >= th.HotHeatIndex:
+		return "热"
+	case comfort.WindChill <= th.ColdWindChill:
+		return "冷"
+	}
+	return ""
+}
+
+// scoredCondition 参与主导天气判断的推导天气状况，未开启 Scored 或与记录的天气状况相同时返回空字符串
+func (th ComfortThresholds) scoredCondition(r AnalyzedRecord) string {
+	if !th.Scored {
+		return ""
+	}
+	if derived := th.derivedCondition(r.Comfort); derived != r.Condition {
+		return derived
+	}
+	return ""
+}
+
+// comfortStats 体感指标统计
+type comfortStats struct {
+	maxHeatIndex, minWindChill AnalyzedRecord
+	avgDewPoint, avgApparent   float64
+	minApparent, maxApparent   float64
+	// derived 按体感指标推导的“热”“冷”天气持续时长
+	derived map[string]time.Duration
+}
+
+// analyzeComfort 统计最大炎热指数、最低风寒温度、按时长加权的平均露点、平均体感温度及推导的“热”“冷”天气时长
+// 推导的天气默认只作为体感标注，开启 Scored 时另由 buildConditionStats 计入主导天气判断
+func analyzeComfort(records []AnalyzedRecord, totalHours float64, th ComfortThresholds) comfortStats {
+	stats := comfortStats{
+		maxHeatIndex: records[0],
+		minWindChill: records[0],
+		minApparent:  records[0].Comfort.ApparentTemperature,
+		maxApparent:  records[0].Comfort.ApparentTemperature,
+	}
+	for _, r := range records {
+		if r.Comfort.HeatIndex > stats.maxHeatIndex.Comfort.HeatIndex {
+			stats.maxHeatIndex = r
+		}
+		if r.Comfort.WindChill < stats.minWindChill.Comfort.WindChill {
+			stats.minWindChill = r
+		}
+		stats.minApparent = math.Min(stats.minApparent, r.Comfort.ApparentTemperature)
+		stats.maxApparent = math.Max(stats.maxApparent, r.Comfort.ApparentTemperature)
+		stats.avgDewPoint += r.Comfort.DewPoint * r.Duration.Hours()
+		stats.avgApparent += r.Comfort.ApparentTemperature * r.Duration.Hours()
+		if derived := th.derivedCondition(r.Comfort); derived != "" && derived != r.Condition {
+			if stats.derived == nil {
+				stats.derived = make(map[string]time.Duration)
+			}
+			stats.derived[derived] += r.Duration
+		}
+	}
+	if totalHours > 0 {
+		stats.avgDewPoint /= totalHours
+		stats.avgApparent /= totalHours
+	}
+	return stats
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComfortIndices(t *testing.T) {
+	cases := []struct {
+		name     string
+		actual   float64
+		expected float64
+	}{
+		{"露点", DewPoint(25, 60), 16.7},
+		{"炎热指数", HeatIndex(32, 70), 40.4},
+		{"低温时炎热指数", HeatIndex(20, 50), 19.4},
+		{"风寒温度", WindChill(-10, 30/3.6), -19.5},
+		{"较暖时风寒温度", WindChill(15, 10), 15},
+		{"体感温度", ApparentTemperature(25, 50, 2), 24.8},
+	}
+	for _, c := range cases {
+		if math.Abs(c.actual-c.expected) > 0.2 {
+			t.Errorf("%s错误，期望 %.1f，实际 %.2f", c.name, c.expected, c.actual)
+		}
+	}
+}
+
+func TestDerivedHotCondition(t *testing.T) {
+	temperatures := []float64{28, 30, 33, 35, 34, 31}
+	var conditions []WeatherCondition
+	for i, temperature := range temperatures {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", i+10),
+			Temperature: temperature,
+			Condition:   "晴",
+			Humidity:    65.0,
+			WindSpeed:   1.5,
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	// 33°C及以上、湿度65%时炎热指数超过35°C
+	if d := result.ComfortConditions["热"].Hours(); d != 4 {
+		t.Errorf("热天气时长错误，期望 4 小时，实际 %.1f 小时", d)
+	}
+	// 推导的热天气只作体感标注，不重复计入天气状况时长
+	if _, ok := result.ConditionDurations["热"]; ok || result.ConditionDurations["晴"].Hours() != 6 {
+		t.Errorf("天气状况时长错误: %v", result.ConditionDurations)
+	}
+	if result.MaxHeatIndexTime.Hour() != 13 || result.MaxHeatIndex < 45 {
+		t.Errorf("最大炎热指数错误: %.1f°C，时间 %v", result.MaxHeatIndex, result.MaxHeatIndexTime)
+	}
+	if result.Records[0].Comfort.DewPoint == 0 || result.AverageApparentTemperature <= result.AverageTemperature {
+		t.Errorf("体感指标错误，平均体感温度 %.1f°C", result.AverageApparentTemperature)
+	}
+
+	analyzer.SetComfortThresholds(ComfortThresholds{})
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.ComfortConditions) != 0 {
+		t.Error("关闭推导后不应出现热天气")
+	}
+}
+
+func TestHotSunnyDayDominance(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 24; hour++ {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 34.0,
+			Condition:   "晴",
+			Humidity:    60.0,
+			WindSpeed:   1.0,
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.DominantCondition != "晴" || len(result.OtherConditions) != 0 || result.Confidence != 1 {
+		t.Errorf("炎热晴天的主要天气应为晴，实际 %s，其他 %v，置信度 %.2f", result.DominantCondition, result.OtherConditions, result.Confidence)
+	}
+	if result.ComfortConditions["热"] != 24*time.Hour {
+		t.Errorf("热天气时长错误，实际 %v", result.ComfortConditions["热"])
+	}
+
+	// 开启 Scored 后推导的热天气与晴并列计时，按权重表中热的权重(0.5)胜过晴(0.35)
+	th := DefaultComfortThresholds()
+	th.Scored = true
+	analyzer.SetComfortThresholds(th)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.DominantCondition != "热" {
+		t.Errorf("推导天气参与评分时主要天气应为热，实际 %s", result.DominantCondition)
+	}
+	if result.ConditionDurations["热"] != 24*time.Hour || result.ConditionDurations["晴"] != 24*time.Hour {
+		t.Errorf("天气状况时长错误: %v", result.ConditionDurations)
+	}
+}
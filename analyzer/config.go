@@ -0,0 +1,408 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// AnalyzerConfig 分析器配置，由 NewAnalyzerConfig 按选项创建，创建后只读，可在多个 goroutine 间共享
+type AnalyzerConfig struct {
+	// 无效记录处理策略，为 InvalidRecordReject 以外的策略时在分析阶段校验
+	invalidRecordPolicy InvalidRecordPolicy
+	// 数据校验规则
+	validationRules ValidationRules
+	// 解析不含时区偏移的观测时间所用时区
+	location *time.Location
+	// 重复时间记录处理策略
+	duplicatePolicy DuplicatePolicy
+	// 期望的观测间隔，为0时自动判断
+	expectedInterval time.Duration
+	// 期望的观测时间范围，为零值时以实际数据为准
+	expectedStart, expectedEnd time.Time
+	// 缺测填补选项
+	gapFill GapFillOptions
+	// 天气转变的最短持续时长，更短的变化视为短暂波动
+	transitionMinPersistence time.Duration
+	// 天气状况与实测降水强度不符时的处理策略
+	intensityMismatchPolicy IntensityMismatchPolicy
+	// 阵风判定阈值（米/秒），风速超出相邻记录平均风速该值及以上时视为阵风
+	gustThreshold float64
+	// 按体感指标推导“热”“冷”天气的阈值
+	comfortThresholds ComfortThresholds
+	// 按数值推断天气状况的方式
+	inferenceMode InferenceMode
+	// 是否先按天气大类判断主导天气
+	familyAggregation bool
+	// 主导天气策略
+	dominanceStrategy DominanceStrategy
+	// 是否给出主导天气判断过程
+	explain bool
+	// 降水量达到阈值时的权重调整系数
+	precipitationBoostFactor float64
+	// 风速达到阈值时的权重调整系数
+	windSpeedBoostFactor float64
+	// 其他重要天气状况的最低得分占比（%）
+	otherConditionMinShare float64
+	// 其他重要天气状况的最大数量，为0时不限
+	maxOtherConditions int
+	// 天气状况权重映射
+	conditionWeights map[string]float64
+	// 天气状况别名
+	conditionAliases map[string]string
+	// 降水量阈值（毫米/小时）
+	precipitationThresholds map[string]float64
+	// 风速阈值（米/秒）
+	windSpeedThresholds map[string]float64
+	// 已应用的区域气候配置名称
+	profile string
+	// 日志，为空时使用全局日志
+	logger utils.Logger
+}
+
+// Option 分析器配置选项，选项无效时返回错误
+type Option func(*AnalyzerConfig) error
+
+// NewAnalyzerConfig 以默认配置为基础按顺序应用选项创建分析器配置
+func NewAnalyzerConfig(opts ...Option) (*AnalyzerConfig, error) {
+	cfg := defaultAnalyzerConfig()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// defaultAnalyzerConfig 默认分析器配置
+func defaultAnalyzerConfig() *AnalyzerConfig {
+	return &AnalyzerConfig{
+		validationRules:          DefaultValidationRules(),
+		location:                 time.Local,
+		transitionMinPersistence: 2 * time.Hour,
+		gustThreshold:            5.0,
+		comfortThresholds:        DefaultComfortThresholds(),
+		dominanceStrategy:        WeightedStrategy{},
+		precipitationBoostFactor: *embeddedDefaults.BoostFactors.Precipitation,
+		windSpeedBoostFactor:     *embeddedDefaults.BoostFactors.WindSpeed,
+		otherConditionMinShare:   *embeddedDefaults.OtherConditionMinShare,
+		maxOtherConditions:       *embeddedDefaults.MaxOtherConditions,
+		conditionWeights:         defaultConditionWeights(),
+		conditionAliases:         copyMap(defaultConditionAliases),
+		precipitationThresholds:  defaultPrecipitationThresholds(),
+		windSpeedThresholds:      defaultWindSpeedThresholds(),
+	}
+}
+
+// With 以当前配置为基础应用选项创建新配置，当前配置不受影响
+func (c *AnalyzerConfig) With(opts ...Option) (*AnalyzerConfig, error) {
+	cfg := c.clone()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// clone 复制配置，映射表同时复制
+func (c *AnalyzerConfig) clone() *AnalyzerConfig {
+	cfg := *c
+	cfg.conditionWeights = copyMap(c.conditionWeights)
+	cfg.conditionAliases = copyMap(c.conditionAliases)
+	cfg.precipitationThresholds = copyMap(c.precipitationThresholds)
+	cfg.windSpeedThresholds = copyMap(c.windSpeedThresholds)
+	return &cfg
+}
+
+// log 获取分析器日志
+func (c *AnalyzerConfig) log() utils.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return utils.GetLogger()
+}
+
+// ConditionWeights 天气状况权重的副本
+func (c *AnalyzerConfig) ConditionWeights() map[string]float64 {
+	return copyMap(c.conditionWeights)
+}
+
+// PrecipitationThresholds 降水量阈值的副本
+func (c *AnalyzerConfig) PrecipitationThresholds() map[string]float64 {
+	return copyMap(c.precipitationThresholds)
+}
+
+// WindSpeedThresholds 风速阈值的副本
+func (c *AnalyzerConfig) WindSpeedThresholds() map[string]float64 {
+	return copyMap(c.windSpeedThresholds)
+}
+
+// ConditionAliases 天气状况别名的副本
+func (c *AnalyzerConfig) ConditionAliases() map[string]string {
+	return copyMap(c.conditionAliases)
+}
+
+// DominanceStrategy 主导天气策略
+func (c *AnalyzerConfig) DominanceStrategy() DominanceStrategy {
+	return c.dominanceStrategy
+}
+
+// copyMap 复制映射表，nil 复制为空映射表
+func copyMap[V any](m map[string]V) map[string]V {
+	copied := make(map[string]V, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// mergeMap 将 values 合并到 target，同名项覆盖
+func mergeMap[V any](target, values map[string]V) {
+	for k, v := range values {
+		target[k] = v
+	}
+}
+
+// WithInvalidRecordPolicy 设置无效记录处理策略，默认直接返回错误
+func WithInvalidRecordPolicy(policy InvalidRecordPolicy) Option {
+	return func(c *AnalyzerConfig) error {
+		c.invalidRecordPolicy = policy
+		return nil
+	}
+}
+
+// WithValidationRules 设置数据校验规则，应从 DefaultValidationRules() 开始修改
+// 上限不大于下限、风速及降水量上限或合理性容差不大于0时返回 ErrInvalidConfig 错误，如只设置了部分字段的规则
+func WithValidationRules(rules ValidationRules) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(validationRuleErrors(rules)); err != nil {
+			return err
+		}
+		c.validationRules = rules
+		return nil
+	}
+}
+
+// WithLocation 设置解析不含时区偏移的观测时间所用时区，默认为 time.Local，传入 nil 时使用 time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(c *AnalyzerConfig) error {
+		if loc == nil {
+			loc = time.Local
+		}
+		c.location = loc
+		return nil
+	}
+}
+
+// WithDuplicatePolicy 设置重复时间记录处理策略，默认仅报告不处理
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(c *AnalyzerConfig) error {
+		c.duplicatePolicy = policy
+		return nil
+	}
+}
+
+// WithExpectedInterval 设置期望的观测间隔，为0时取相邻记录间隔的中位数
+func WithExpectedInterval(interval time.Duration) Option {
+	return func(c *AnalyzerConfig) error {
+		c.expectedInterval = interval
+		return nil
+	}
+}
+
+// WithExpectedPeriod 设置期望的观测时间范围(首尾均包含)，用于检测数据首尾的缺测
+func WithExpectedPeriod(start, end time.Time) Option {
+	return func(c *AnalyzerConfig) error {
+		c.expectedStart, c.expectedEnd = start, end
+		return nil
+	}
+}
+
+// WithGapFill 设置缺测填补选项，默认不填补
+func WithGapFill(opts GapFillOptions) Option {
+	return func(c *AnalyzerConfig) error {
+		c.gapFill = opts
+		return nil
+	}
+}
+
+// WithTransitionMinPersistence 设置天气转变的最短持续时长，默认2小时
+func WithTransitionMinPersistence(d time.Duration) Option {
+	return func(c *AnalyzerConfig) error {
+		c.transitionMinPersistence = d
+		return nil
+	}
+}
+
+// WithIntensityMismatchPolicy 设置天气状况与实测降水强度不符时的处理策略，默认仅标记
+func WithIntensityMismatchPolicy(policy IntensityMismatchPolicy) Option {
+	return func(c *AnalyzerConfig) error {
+		c.intensityMismatchPolicy = policy
+		return nil
+	}
+}
+
+// WithGustThreshold 设置阵风判定阈值（米/秒），默认5米/秒，为0时不检测阵风
+func WithGustThreshold(threshold float64) Option {
+	return func(c *AnalyzerConfig) error {
+		c.gustThreshold = threshold
+		return nil
+	}
+}
+
+// WithComfortThresholds 设置按体感指标推导“热”“冷”天气的阈值及推导结果是否参与主导天气判断
+func WithComfortThresholds(th ComfortThresholds) Option {
+	return func(c *AnalyzerConfig) error {
+		c.comfortThresholds = th
+		return nil
+	}
+}
+
+// WithInferenceMode 设置按数值推断天气状况的方式，默认仅推断天气状况为空或“未知”的记录
+func WithInferenceMode(mode InferenceMode) Option {
+	return func(c *AnalyzerConfig) error {
+		c.inferenceMode = mode
+		return nil
+	}
+}
+
+// WithFamilyAggregation 设置是否先按天气大类汇总判断主导天气，默认关闭
+func WithFamilyAggregation(enabled bool) Option {
+	return func(c *AnalyzerConfig) error {
+		c.familyAggregation = enabled
+		return nil
+	}
+}
+
+// WithDominanceStrategy 设置主导天气策略，默认为加权时长策略，传入 nil 时恢复默认
+func WithDominanceStrategy(strategy DominanceStrategy) Option {
+	return func(c *AnalyzerConfig) error {
+		if strategy == nil {
+			strategy = WeightedStrategy{}
+		}
+		c.dominanceStrategy = strategy
+		return nil
+	}
+}
+
+// WithDominanceStrategyName 按名称设置已注册的主导天气策略
+func WithDominanceStrategyName(name string) Option {
+	return func(c *AnalyzerConfig) error {
+		strategy, ok := LookupDominanceStrategy(name)
+		if !ok {
+			return &utils.WeatherError{
+				Code:    utils.ErrInvalidInput,
+				Message: fmt.Sprintf("未注册的主导天气策略: %s", name),
+				Value:   name,
+			}
+		}
+		c.dominanceStrategy = strategy
+		return nil
+	}
+}
+
+// WithExplain 设置是否在分析结果中给出主导天气判断过程，默认关闭
+func WithExplain(enabled bool) Option {
+	return func(c *AnalyzerConfig) error {
+		c.explain = enabled
+		return nil
+	}
+}
+
+// WithBoostFactors 设置降水量、风速达到阈值时的权重调整系数，默认分别为1.2、1.15
+func WithBoostFactors(precipitation, windSpeed float64) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(boostFactorErrors(&precipitation, &windSpeed)); err != nil {
+			return err
+		}
+		c.precipitationBoostFactor, c.windSpeedBoostFactor = precipitation, windSpeed
+		return nil
+	}
+}
+
+// WithOtherConditionMinShare 设置其他重要天气状况的最低得分占比（%），默认20%
+func WithOtherConditionMinShare(share float64) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(minShareErrors(share)); err != nil {
+			return err
+		}
+		c.otherConditionMinShare = share
+		return nil
+	}
+}
+
+// WithMaxOtherConditions 设置其他重要天气状况的最大数量，默认为0即不限
+func WithMaxOtherConditions(n int) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(maxOtherErrors(n)); err != nil {
+			return err
+		}
+		c.maxOtherConditions = n
+		return nil
+	}
+}
+
+// WithConditionWeights 设置天气状况权重，与默认权重合并，同名项覆盖默认值
+// 权重应在0-1之间，存在无效权重时返回 ErrInvalidConfig 错误且不做任何修改
+func WithConditionWeights(weights map[string]float64) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(weightErrors(weights)); err != nil {
+			return err
+		}
+		mergeMap(c.conditionWeights, weights)
+		return nil
+	}
+}
+
+// WithPrecipitationThresholds 设置降水量阈值，与默认阈值合并，同名项覆盖默认值
+// 阈值不能为负数，合并后同一强度序列的阈值应严格递增（如 暴雨 < 大暴雨 < 特大暴雨），否则返回 ErrInvalidConfig 错误且不做任何修改
+func WithPrecipitationThresholds(thresholds map[string]float64) Option {
+	return func(c *AnalyzerConfig) error {
+		merged, err := mergedThresholds(sectionPrecipitationThresholds, c.precipitationThresholds, thresholds)
+		if err != nil {
+			return err
+		}
+		c.precipitationThresholds = merged
+		return nil
+	}
+}
+
+// WithWindSpeedThresholds 设置风速阈值，与默认阈值合并，同名项覆盖默认值
+// 阈值不能为负数，合并后同一强度序列的阈值应严格递增（如 扬沙 < 沙尘暴 < 强沙尘暴），否则返回 ErrInvalidConfig 错误且不做任何修改
+func WithWindSpeedThresholds(thresholds map[string]float64) Option {
+	return func(c *AnalyzerConfig) error {
+		merged, err := mergedThresholds(sectionWindSpeedThresholds, c.windSpeedThresholds, thresholds)
+		if err != nil {
+			return err
+		}
+		c.windSpeedThresholds = merged
+		return nil
+	}
+}
+
+// WithConditionAliases 设置天气状况别名，与默认别名合并，同名项覆盖默认值
+func WithConditionAliases(aliases map[string]string) Option {
+	return func(c *AnalyzerConfig) error {
+		if err := joinConfigErrors(aliasErrors(aliases)); err != nil {
+			return err
+		}
+		mergeMap(c.conditionAliases, aliases)
+		return nil
+	}
+}
+
+// WithLogger 设置分析器日志，传入 nil 时使用全局日志
+func WithLogger(l utils.Logger) Option {
+	return func(c *AnalyzerConfig) error {
+		c.logger = l
+		return nil
+	}
+}
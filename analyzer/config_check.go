@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// 配置项名称，与配置文件中的键一致
+const (
+	sectionConditionWeights        = "condition_weights"
+	sectionPrecipitationThresholds = "precipitation_thresholds"
+	sectionWindSpeedThresholds     = "wind_speed_thresholds"
+	sectionConditionAliases        = "condition_aliases"
+)
+
+// thresholdLadders 按强度由弱到强排列的天气状况，同一序列中已配置的阈值应严格递增
+var thresholdLadders = map[string][][]string{
+	sectionPrecipitationThresholds: {{"毛毛雨/细雨", "小雨", "中雨", "大雨", "暴雨", "大暴雨", "特大暴雨"}},
+	sectionWindSpeedThresholds:     {{"浮尘", "扬沙", "沙尘暴", "强沙尘暴"}},
+}
+
+// configError 单个配置项错误
+func configError(field string, value any, format string, args ...any) error {
+	return &utils.WeatherError{
+		Code:    utils.ErrInvalidConfig,
+		Message: fmt.Sprintf(format, args...),
+		Field:   field,
+		Value:   value,
+	}
+}
+
+// joinConfigErrors 合并配置项错误，按配置项排序后列出全部错误信息，没有错误时返回 nil
+func joinConfigErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*utils.WeatherError).Field < errs[j].(*utils.WeatherError).Field
+	})
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.(*utils.WeatherError).Message
+	}
+	return &utils.WeatherError{
+		Code:    utils.ErrInvalidConfig,
+		Message: "配置无效: " + strings.Join(messages, "；"),
+		Err:     errors.Join(errs...),
+	}
+}
+
+// valueErrors 检查映射表的名称非空且值在 [min, max] 范围内
+func valueErrors(section string, values map[string]float64, min, max float64, rule string) []error {
+	var errs []error
+	for _, name := range sortedKeys(values) {
+		value := values[name]
+		field := section + "." + name
+		switch {
+		case strings.TrimSpace(name) == "":
+			errs = append(errs, configError(field, value, "%s: 名称不能为空", section))
+		case math.IsNaN(value) || value < min || value > max:
+			errs = append(errs, configError(field, value, "%s: %s，实际为 %g", field, rule, value))
+		}
+	}
+	return errs
+}
+
+// weightErrors 检查天气状况权重，权重应在0-1之间
+func weightErrors(weights map[string]float64) []error {
+	return valueErrors(sectionConditionWeights, weights, 0, 1, "权重应在0-1之间")
+}
+
+// thresholdErrors 检查阈值取值及强度顺序
+func thresholdErrors(section string, thresholds map[string]float64) []error {
+	rule := "阈值应为不小于0的有限数"
+	switch section {
+	case sectionPrecipitationThresholds:
+		rule = "降水量阈值应为不小于0的有限数"
+	case sectionWindSpeedThresholds:
+		rule = "风速阈值应为不小于0的有限数"
+	}
+	errs := valueErrors(section, thresholds, 0, math.MaxFloat64, rule)
+	if len(errs) > 0 {
+		return errs
+	}
+	return thresholdOrderErrors(section, thresholds)
+}
+
+// thresholdOrderErrors 检查同一强度序列中的阈值是否严格递增，如 暴雨 < 大暴雨 < 特大暴雨
+func thresholdOrderErrors(section string, thresholds map[string]float64) []error {
+	var errs []error
+	for _, ladder := range thresholdLadders[section] {
+		prev := ""
+		for _, condition := range ladder {
+			value, ok := thresholds[condition]
+			if !ok {
+				continue
+			}
+			if prev != "" && value <= thresholds[prev] {
+				errs = append(errs, configError(section+"."+condition, value,
+					"%s: %s的阈值(%g)应大于%s的阈值(%g)", section, condition, value, prev, thresholds[prev]))
+			}
+			prev = condition
+		}
+	}
+	return errs
+}
+
+// mergedThresholds 合并阈值并检查合并后的取值及强度顺序，有错误时返回 nil 及错误
+func mergedThresholds(section string, current, values map[string]float64) (map[string]float64, error) {
+	merged := copyMap(current)
+	mergeMap(merged, values)
+	if err := joinConfigErrors(thresholdErrors(section, merged)); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// checkThresholdOrder 检查配置中各阈值的强度顺序
+func (c *AnalyzerConfig) checkThresholdOrder() error {
+	errs := thresholdOrderErrors(sectionPrecipitationThresholds, c.precipitationThresholds)
+	errs = append(errs, thresholdOrderErrors(sectionWindSpeedThresholds, c.windSpeedThresholds)...)
+	return joinConfigErrors(errs)
+}
+
+// Warnings 不影响使用但可能配置有误的项，如为没有权重的天气状况设置的阈值不会生效，按配置项排序
+func (c *AnalyzerConfig) Warnings() []string {
+	var warnings []string
+	unknown := func(section string, names []string, targets map[string]string) {
+		for _, name := range names {
+			condition := name
+			if targets != nil {
+				condition = targets[name]
+			}
+			if _, ok := c.conditionWeights[condition]; !ok {
+				warnings = append(warnings, fmt.Sprintf("%s.%s: 天气状况%s没有权重，该项不会生效", section, name, condition))
+			}
+		}
+	}
+	unknown(sectionConditionAliases, sortedKeys(c.conditionAliases), c.conditionAliases)
+	unknown(sectionPrecipitationThresholds, sortedKeys(c.precipitationThresholds), nil)
+	unknown(sectionWindSpeedThresholds, sortedKeys(c.windSpeedThresholds), nil)
+	return warnings
+}
+
+// boostFactorErrors 检查权重调整系数，系数应为大于0的有限数，nil 表示不修改
+func boostFactorErrors(precipitation, windSpeed *float64) []error {
+	var errs []error
+	for _, item := range []struct {
+		field  string
+		factor *float64
+	}{
+		{"boost_factors.precipitation", precipitation},
+		{"boost_factors.wind_speed", windSpeed},
+	} {
+		if f := item.factor; f != nil && (math.IsNaN(*f) || math.IsInf(*f, 0) || *f <= 0) {
+			errs = append(errs, configError(item.field, *f, "%s: 权重调整系数应为大于0的有限数，实际为 %g", item.field, *f))
+		}
+	}
+	return errs
+}
+
+// minShareErrors 检查其他天气状况的最小得分占比，应在0-100之间
+func minShareErrors(share float64) []error {
+	if math.IsNaN(share) || share < 0 || share > 100 {
+		return []error{configError("other_condition_min_share", share,
+			"other_condition_min_share: 得分占比应在0-100之间，实际为 %g", share)}
+	}
+	return nil
+}
+
+// maxOtherErrors 检查其他天气状况的最大数量，不能小于0
+func maxOtherErrors(n int) []error {
+	if n < 0 {
+		return []error{configError("max_other_conditions", n, "max_other_conditions: 数量不能小于0，实际为 %d", n)}
+	}
+	return nil
+}
+
+// aliasErrors 检查天气状况别名，别名及对应的天气状况均不能为空
+func aliasErrors(aliases map[string]string) []error {
+	var errs []error
+	for _, alias := range sortedKeys(aliases) {
+		if strings.TrimSpace(alias) == "" || strings.TrimSpace(aliases[alias]) == "" {
+			errs = append(errs, configError(sectionConditionAliases+"."+alias, aliases[alias],
+				"%s: 别名及对应的天气状况不能为空", sectionConditionAliases))
+		}
+	}
+	return errs
+}
+
+// validationRuleErrors 检查数据校验规则，各上限应大于对应下限，风速、降水量上限及合理性容差应大于0
+// 零值或只设置了部分字段的规则会将几乎所有记录判为无效，应从 DefaultValidationRules 开始修改
+func validationRuleErrors(r ValidationRules) []error {
+	var errs []error
+	check := func(name string, value float64, ok bool, rule string) {
+		if math.IsNaN(value) || !ok {
+			field := "validation_rules." + name
+			errs = append(errs, configError(field, value, "%s: %s，实际为 %g，请从 DefaultValidationRules() 开始修改", field, rule, value))
+		}
+	}
+	check("MaxTemperature", r.MaxTemperature, r.MaxTemperature > r.MinTemperature, "温度上限应大于下限")
+	check("MaxHumidity", r.MaxHumidity, r.MaxHumidity > r.MinHumidity, "湿度上限应大于下限")
+	check("MaxWindSpeed", r.MaxWindSpeed, r.MaxWindSpeed > 0, "风速上限应大于0")
+	check("MaxPrecipitation", r.MaxPrecipitation, r.MaxPrecipitation > 0, "降水量上限应大于0")
+	check("MaxSnowTemperature", r.MaxSnowTemperature, true, "降雪最高合理温度不能为NaN")
+	check("MaxDewPointDeviation", r.MaxDewPointDeviation, r.MaxDewPointDeviation > 0, "露点最大偏差应大于0")
+	check("MaxHourlyTemperatureChange", r.MaxHourlyTemperatureChange, r.MaxHourlyTemperatureChange > 0, "温度变化上限应大于0")
+	return errs
+}
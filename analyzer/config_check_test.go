@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestSetCustomWeightsValidate(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0},
+	}
+	for _, weights := range []map[string]float64{
+		{"晴": -0.1},
+		{"晴": math.NaN()},
+		{"晴": 1.5},
+		{"多云": 0.5, "": 0.5},
+	} {
+		analyzer, err := NewWeatherAnalyzer(conditions)
+		if err != nil {
+			t.Fatalf("创建天气分析器失败: %v", err)
+		}
+		err = analyzer.SetCustomWeights(weights)
+		if !errors.Is(err, utils.ErrInvalidConfig) {
+			t.Errorf("无效权重 %v 应返回 ErrInvalidConfig，实际为 %v", weights, err)
+		}
+		if analyzer.cfg.conditionWeights["晴"] != 0.35 || analyzer.cfg.conditionWeights["多云"] != defaultConditionWeights()["多云"] {
+			t.Errorf("无效权重 %v 不应修改任何配置", weights)
+		}
+	}
+
+	analyzer, _ := NewWeatherAnalyzer(conditions)
+	var buf bytes.Buffer
+	analyzer.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	if err := analyzer.SetCustomWeights(map[string]float64{"晴": 0.4}); err != nil {
+		t.Fatalf("设置有效权重失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "old_value=0.35") {
+		t.Errorf("覆盖权重日志应给出原权重: %s", buf.String())
+	}
+}
+
+func TestSetCustomThresholdsValidate(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+
+	err = analyzer.SetCustomPrecipitationThresholds(map[string]float64{"暴雨": 80})
+	if !errors.Is(err, utils.ErrInvalidConfig) || !strings.Contains(err.Error(), "大暴雨的阈值(70)应大于暴雨的阈值(80)") {
+		t.Errorf("降水量阈值顺序错误应返回错误，实际为 %v", err)
+	}
+	if analyzer.cfg.precipitationThresholds["暴雨"] != 50 {
+		t.Error("无效降水量阈值不应修改任何配置")
+	}
+	if err := analyzer.SetCustomPrecipitationThresholds(map[string]float64{"暴雨": 80, "大暴雨": 120, "特大暴雨": 160}); err != nil {
+		t.Errorf("同时调整降水量阈值失败: %v", err)
+	}
+
+	err = analyzer.SetCustomWindSpeedThresholds(map[string]float64{"扬沙": -1, "沙尘暴": math.Inf(1)})
+	var we *utils.WeatherError
+	if !errors.As(err, &we) || strings.Count(we.Message, "风速阈值应为不小于0的有限数") != 2 {
+		t.Errorf("应列出全部无效风速阈值，实际为 %v", err)
+	}
+
+	if _, err := NewAnalyzerConfig(WithWindSpeedThresholds(map[string]float64{"强沙尘暴": 10})); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("风速阈值顺序错误应返回错误，实际为 %v", err)
+	}
+	if _, err := NewAnalyzerConfig(WithConditionWeights(map[string]float64{"晴": 2})); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("无效权重应返回错误，实际为 %v", err)
+	}
+	if _, err := NewAnalyzerConfig(WithBoostFactors(0, 1)); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("无效权重调整系数应返回错误，实际为 %v", err)
+	}
+	f := &ConfigFile{PrecipitationThresholds: map[string]float64{"大暴雨": 30}}
+	if _, err := NewAnalyzerConfig(WithConfigFile(f)); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("与默认阈值合并后顺序错误应返回错误，实际为 %v", err)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	if warnings := defaultAnalyzerConfig().Warnings(); len(warnings) != 0 {
+		t.Errorf("默认配置不应有警告: %v", warnings)
+	}
+
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0},
+		{Time: "2024-01-01 01:00", Temperature: 26.0, Condition: "晴", Humidity: 60.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLogger(utils.NopLogger)
+	// 阈值按规范化后的天气状况生效，别名“雷雨”的阈值不会生效
+	if err := analyzer.SetCustomPrecipitationThresholds(map[string]float64{"雷雨": 30}); err != nil {
+		t.Fatalf("设置降水量阈值失败: %v", err)
+	}
+	if err := analyzer.SetCustomWindSpeedThresholds(map[string]float64{"大风": 10.8}); err != nil {
+		t.Fatalf("设置风速阈值失败: %v", err)
+	}
+	analyzer.SetConditionAliases(map[string]string{"大太阳": "烈日"})
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	want := []string{
+		"condition_aliases.大太阳: 天气状况烈日没有权重，该项不会生效",
+		"precipitation_thresholds.雷雨: 天气状况雷雨没有权重，该项不会生效",
+		"wind_speed_thresholds.大风: 天气状况大风没有权重，该项不会生效",
+	}
+	if strings.Join(result.ConfigWarnings, "|") != strings.Join(want, "|") {
+		t.Errorf("配置警告错误: %v", result.ConfigWarnings)
+	}
+}
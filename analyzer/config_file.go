@@ -0,0 +1,366 @@
+package analyzer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+//go:generate go run ../internal/gencfgdoc -o ../DefaultCfg.md
+
+// ConfigFileVersion 当前配置文件版本
+const ConfigFileVersion = 1
+
+// ConfigFormat 配置文件格式
+type ConfigFormat string
+
+const (
+	// ConfigJSON JSON格式
+	ConfigJSON ConfigFormat = "json"
+	// ConfigYAML YAML格式
+	ConfigYAML ConfigFormat = "yaml"
+	// ConfigTOML TOML格式
+	ConfigTOML ConfigFormat = "toml"
+)
+
+// defaultsJSON 内置默认配置，同时用于生成 DefaultCfg.md
+//
+//go:embed defaults.json
+var defaultsJSON []byte
+
+// embeddedDefaults 解析后的内置默认配置
+var embeddedDefaults = mustParseDefaults()
+
+// BoostFactors 权重调整系数，为空的项保持原值
+type BoostFactors struct {
+	// Precipitation 最大24小时降水量达到降水量阈值时的权重调整系数
+	Precipitation *float64 `json:"precipitation,omitempty" yaml:"precipitation,omitempty" toml:"precipitation,omitempty"`
+	// WindSpeed 最大风速达到风速阈值时的权重调整系数
+	WindSpeed *float64 `json:"wind_speed,omitempty" yaml:"wind_speed,omitempty" toml:"wind_speed,omitempty"`
+}
+
+// ConfigFile 分析器配置文件，各项均可省略，省略的项保持原值，映射表与原值合并
+type ConfigFile struct {
+	// Version 配置文件版本，省略时视为当前版本
+	Version int `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	// Description 配置说明，不影响分析
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	// Profile 作为基础的区域气候配置，如 south-china，先应用区域气候配置再应用本文件中的其余项
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty" toml:"profile,omitempty"`
+	// ConditionWeights 天气状况权重(0-1)
+	ConditionWeights map[string]float64 `json:"condition_weights,omitempty" yaml:"condition_weights,omitempty" toml:"condition_weights,omitempty"`
+	// PrecipitationThresholds 降水量阈值（毫米）
+	PrecipitationThresholds map[string]float64 `json:"precipitation_thresholds,omitempty" yaml:"precipitation_thresholds,omitempty" toml:"precipitation_thresholds,omitempty"`
+	// WindSpeedThresholds 风速阈值（米/秒）
+	WindSpeedThresholds map[string]float64 `json:"wind_speed_thresholds,omitempty" yaml:"wind_speed_thresholds,omitempty" toml:"wind_speed_thresholds,omitempty"`
+	// BoostFactors 权重调整系数
+	BoostFactors *BoostFactors `json:"boost_factors,omitempty" yaml:"boost_factors,omitempty" toml:"boost_factors,omitempty"`
+	// OtherConditionMinShare 其他重要天气状况的最低得分占比（%）
+	OtherConditionMinShare *float64 `json:"other_condition_min_share,omitempty" yaml:"other_condition_min_share,omitempty" toml:"other_condition_min_share,omitempty"`
+	// MaxOtherConditions 其他重要天气状况的最大数量，0表示不限
+	MaxOtherConditions *int `json:"max_other_conditions,omitempty" yaml:"max_other_conditions,omitempty" toml:"max_other_conditions,omitempty"`
+	// Notes 各项的说明，按配置项、名称分组，仅用于生成文档，不影响分析
+	Notes map[string]map[string]string `json:"notes,omitempty" yaml:"notes,omitempty" toml:"notes,omitempty"`
+}
+
+// mustParseDefaults 解析内置默认配置，内置配置无效属于编程错误
+func mustParseDefaults() *ConfigFile {
+	f, err := ParseConfig(defaultsJSON, ConfigJSON)
+	if err != nil {
+		panic(fmt.Sprintf("内置默认配置无效: %v", err))
+	}
+	return f
+}
+
+// DefaultConfigFile 内置默认配置的副本，含各项说明
+func DefaultConfigFile() *ConfigFile {
+	return embeddedDefaults.clone()
+}
+
+// clone 复制配置文件
+func (f *ConfigFile) clone() *ConfigFile {
+	copied := *f
+	copied.ConditionWeights = copyMap(f.ConditionWeights)
+	copied.PrecipitationThresholds = copyMap(f.PrecipitationThresholds)
+	copied.WindSpeedThresholds = copyMap(f.WindSpeedThresholds)
+	if f.BoostFactors != nil {
+		boost := *f.BoostFactors
+		copied.BoostFactors = &boost
+	}
+	if f.Notes != nil {
+		copied.Notes = make(map[string]map[string]string, len(f.Notes))
+		for section, notes := range f.Notes {
+			copied.Notes[section] = copyMap(notes)
+		}
+	}
+	return &copied
+}
+
+// configFormatOf 按扩展名判断配置文件格式
+func configFormatOf(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigJSON, nil
+	case ".yaml", ".yml":
+		return ConfigYAML, nil
+	case ".toml":
+		return ConfigTOML, nil
+	}
+	return "", &utils.WeatherError{
+		Code:    utils.ErrInvalidConfig,
+		Message: "无法识别的配置文件格式，支持 .json、.yaml、.yml、.toml",
+		Value:   path,
+	}
+}
+
+// LoadConfigFile 读取并校验配置文件，格式按扩展名判断
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	format, err := configFormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrReadFile,
+			Message: "读取配置文件失败",
+			Value:   path,
+			Err:     err,
+		}
+	}
+	f, err := ParseConfig(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ParseConfig 解析并校验配置，不允许出现未定义的配置项
+func ParseConfig(data []byte, format ConfigFormat) (*ConfigFile, error) {
+	f := &ConfigFile{}
+	var err error
+	switch format {
+	case ConfigJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(f)
+	case ConfigYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(f); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ConfigTOML:
+		var md toml.MetaData
+		md, err = toml.NewDecoder(bytes.NewReader(data)).Decode(f)
+		if err == nil {
+			if undecoded := md.Undecoded(); len(undecoded) > 0 {
+				err = fmt.Errorf("未定义的配置项 %q", undecoded[0].String())
+			}
+		}
+	default:
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidConfig,
+			Message: fmt.Sprintf("不支持的配置格式: %s", format),
+			Value:   format,
+		}
+	}
+	if err != nil {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidConfig,
+			Message: fmt.Sprintf("解析%s配置失败", strings.ToUpper(string(format))),
+			Err:     err,
+		}
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Validate 校验配置，返回的错误列出全部无效项
+func (f *ConfigFile) Validate() error {
+	var errs []error
+	invalid := func(field string, value any, format string, args ...any) {
+		errs = append(errs, configError(field, value, format, args...))
+	}
+
+	if f.Version != 0 && f.Version != ConfigFileVersion {
+		invalid("version", f.Version, "version: 不支持的配置版本 %d，当前版本为 %d", f.Version, ConfigFileVersion)
+	}
+	if f.Profile != "" {
+		// 直接检查内置文件是否存在，避免解析内置区域气候配置时递归
+		if _, err := profileFS.Open("profiles/" + f.Profile + ".json"); err != nil {
+			errs = append(errs, unknownProfileError(f.Profile))
+		}
+	}
+	errs = append(errs, weightErrors(f.ConditionWeights)...)
+	errs = append(errs, thresholdErrors(sectionPrecipitationThresholds, f.PrecipitationThresholds)...)
+	errs = append(errs, thresholdErrors(sectionWindSpeedThresholds, f.WindSpeedThresholds)...)
+	if f.BoostFactors != nil {
+		errs = append(errs, boostFactorErrors(f.BoostFactors.Precipitation, f.BoostFactors.WindSpeed)...)
+	}
+	if f.OtherConditionMinShare != nil {
+		errs = append(errs, minShareErrors(*f.OtherConditionMinShare)...)
+	}
+	if f.MaxOtherConditions != nil {
+		errs = append(errs, maxOtherErrors(*f.MaxOtherConditions)...)
+	}
+	return joinConfigErrors(errs)
+}
+
+// sortedKeys 按名称排序的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Marshal 按格式序列化配置
+func (f *ConfigFile) Marshal(format ConfigFormat) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case ConfigJSON:
+		data, err = json.MarshalIndent(f, "", "  ")
+		data = append(data, '\n')
+	case ConfigYAML:
+		data, err = yaml.Marshal(f)
+	case ConfigTOML:
+		var buf bytes.Buffer
+		err = toml.NewEncoder(&buf).Encode(f)
+		data = buf.Bytes()
+	default:
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidConfig,
+			Message: fmt.Sprintf("不支持的配置格式: %s", format),
+			Value:   format,
+		}
+	}
+	if err != nil {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidConfig,
+			Message: fmt.Sprintf("序列化%s配置失败", strings.ToUpper(string(format))),
+			Err:     err,
+		}
+	}
+	return data, nil
+}
+
+// SaveConfigFile 将配置写入文件，格式按扩展名判断
+func SaveConfigFile(path string, f *ConfigFile) error {
+	format, err := configFormatOf(path)
+	if err != nil {
+		return err
+	}
+	data, err := f.Marshal(format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return &utils.WeatherError{
+			Code:    utils.ErrInvalidConfig,
+			Message: "写入配置文件失败",
+			Value:   path,
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// WithConfigFile 应用配置文件中的配置，配置先经校验，映射表与原值合并
+func WithConfigFile(f *ConfigFile) Option {
+	return func(c *AnalyzerConfig) error {
+		if f == nil {
+			return nil
+		}
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		return c.applyCheckedConfigFile(f)
+	}
+}
+
+// WithConfigPath 读取并应用配置文件，格式按扩展名判断
+func WithConfigPath(path string) Option {
+	return func(c *AnalyzerConfig) error {
+		f, err := LoadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		return c.applyCheckedConfigFile(f)
+	}
+}
+
+// applyCheckedConfigFile 应用已校验的配置文件，与原值合并后阈值强度顺序错误时返回错误且不做任何修改
+func (c *AnalyzerConfig) applyCheckedConfigFile(f *ConfigFile) error {
+	next := c.clone()
+	next.applyConfigFile(f)
+	if err := next.checkThresholdOrder(); err != nil {
+		return err
+	}
+	*c = *next
+	return nil
+}
+
+// applyConfigFile 应用已校验的配置文件，指定了区域气候配置时先应用区域气候配置
+func (c *AnalyzerConfig) applyConfigFile(f *ConfigFile) {
+	if profile, ok := loadProfiles()[f.Profile]; ok && f.Profile != "" {
+		c.applyConfigFile(profile)
+		c.profile = f.Profile
+	}
+	mergeMap(c.conditionWeights, f.ConditionWeights)
+	mergeMap(c.precipitationThresholds, f.PrecipitationThresholds)
+	mergeMap(c.windSpeedThresholds, f.WindSpeedThresholds)
+	if f.BoostFactors != nil {
+		if f.BoostFactors.Precipitation != nil {
+			c.precipitationBoostFactor = *f.BoostFactors.Precipitation
+		}
+		if f.BoostFactors.WindSpeed != nil {
+			c.windSpeedBoostFactor = *f.BoostFactors.WindSpeed
+		}
+	}
+	if f.OtherConditionMinShare != nil {
+		c.otherConditionMinShare = *f.OtherConditionMinShare
+	}
+	if f.MaxOtherConditions != nil {
+		c.maxOtherConditions = *f.MaxOtherConditions
+	}
+}
+
+// ConfigFile 导出当前配置，可通过 SaveConfigFile 写入文件
+func (c *AnalyzerConfig) ConfigFile() *ConfigFile {
+	precipitation, windSpeed := c.precipitationBoostFactor, c.windSpeedBoostFactor
+	share, maxOthers := c.otherConditionMinShare, c.maxOtherConditions
+	return &ConfigFile{
+		Version:                 ConfigFileVersion,
+		ConditionWeights:        copyMap(c.conditionWeights),
+		PrecipitationThresholds: copyMap(c.precipitationThresholds),
+		WindSpeedThresholds:     copyMap(c.windSpeedThresholds),
+		BoostFactors:            &BoostFactors{Precipitation: &precipitation, WindSpeed: &windSpeed},
+		OtherConditionMinShare:  &share,
+		MaxOtherConditions:      &maxOthers,
+	}
+}
+
+// ApplyConfigFile 应用配置文件中的配置，配置无效时不做任何修改
+func (wa *WeatherAnalyzer) ApplyConfigFile(f *ConfigFile) error {
+	return wa.apply(WithConfigFile(f))
+}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestDefaultConfigFile(t *testing.T) {
+	f := DefaultConfigFile()
+	if len(f.ConditionWeights) != 53 || len(f.PrecipitationThresholds) != 7 || len(f.WindSpeedThresholds) != 4 {
+		t.Errorf("内置默认配置数量错误: %d %d %d", len(f.ConditionWeights), len(f.PrecipitationThresholds), len(f.WindSpeedThresholds))
+	}
+	for condition := range f.ConditionWeights {
+		if f.Notes["condition_weights"][condition] == "" {
+			t.Errorf("天气状况 %s 缺少说明", condition)
+		}
+	}
+	f.ConditionWeights["晴"] = 0.9
+	if defaultConditionWeights()["晴"] != 0.35 {
+		t.Error("修改默认配置副本不应影响内置默认配置")
+	}
+}
+
+func TestConfigFileRoundTrip(t *testing.T) {
+	cfg, err := NewAnalyzerConfig(WithBoostFactors(1.5, 1.1), WithMaxOtherConditions(3))
+	if err != nil {
+		t.Fatalf("创建分析器配置失败: %v", err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"cfg.json", "cfg.yaml", "cfg.toml"} {
+		path := filepath.Join(dir, name)
+		if err := SaveConfigFile(path, cfg.ConfigFile()); err != nil {
+			t.Fatalf("写入配置文件 %s 失败: %v", name, err)
+		}
+		loaded, err := NewAnalyzerConfig(WithConfigPath(path))
+		if err != nil {
+			t.Fatalf("读取配置文件 %s 失败: %v", name, err)
+		}
+		if loaded.precipitationBoostFactor != 1.5 || loaded.windSpeedBoostFactor != 1.1 || loaded.maxOtherConditions != 3 {
+			t.Errorf("%s 读取的配置错误: %v %v %v", name, loaded.precipitationBoostFactor, loaded.windSpeedBoostFactor, loaded.maxOtherConditions)
+		}
+		if loaded.ConditionWeights()["暴雨"] != 0.955 {
+			t.Errorf("%s 读取的权重错误", name)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cases := []struct {
+		format ConfigFormat
+		data   string
+	}{
+		{ConfigJSON, `{"condition_weights": {"晴": 0.4}, "max_other_conditions": 2}`},
+		{ConfigYAML, "condition_weights:\n  晴: 0.4\nmax_other_conditions: 2\n"},
+		{ConfigTOML, "max_other_conditions = 2\n[condition_weights]\n\"晴\" = 0.4\n"},
+	}
+	for _, c := range cases {
+		f, err := ParseConfig([]byte(c.data), c.format)
+		if err != nil {
+			t.Fatalf("解析%s配置失败: %v", c.format, err)
+		}
+		cfg, err := NewAnalyzerConfig(WithConfigFile(f))
+		if err != nil {
+			t.Fatalf("应用%s配置失败: %v", c.format, err)
+		}
+		if cfg.ConditionWeights()["晴"] != 0.4 || cfg.ConditionWeights()["阴"] != 0.65 || cfg.maxOtherConditions != 2 {
+			t.Errorf("%s配置应与默认配置合并", c.format)
+		}
+		if cfg.otherConditionMinShare != 20 {
+			t.Errorf("%s配置省略的项应保持默认值，实际 %v", c.format, cfg.otherConditionMinShare)
+		}
+	}
+
+	for _, c := range []struct {
+		format ConfigFormat
+		data   string
+	}{
+		{ConfigJSON, `{"condition_weight": {"晴": 0.4}}`},
+		{ConfigYAML, "condition_weight:\n  晴: 0.4\n"},
+		{ConfigTOML, "[condition_weight]\n\"晴\" = 0.4\n"},
+	} {
+		if _, err := ParseConfig([]byte(c.data), c.format); !errors.Is(err, utils.ErrInvalidConfig) {
+			t.Errorf("%s配置含未定义的配置项时应返回 ErrInvalidConfig，实际 %v", c.format, err)
+		}
+	}
+}
+
+func TestConfigFileValidate(t *testing.T) {
+	data := `{
+		"version": 2,
+		"condition_weights": {"晴": 1.5},
+		"wind_speed_thresholds": {"扬沙": -1},
+		"boost_factors": {"precipitation": 0},
+		"other_condition_min_share": 120
+	}`
+	_, err := ParseConfig([]byte(data), ConfigJSON)
+	if !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Fatalf("无效配置应返回 ErrInvalidConfig，实际 %v", err)
+	}
+	for _, field := range []string{"version", "condition_weights.晴", "wind_speed_thresholds.扬沙", "boost_factors.precipitation", "other_condition_min_share"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("错误信息应包含 %s: %v", field, err)
+		}
+	}
+
+	analyzer := newWeatherAnalyzer(nil, InvalidRecordReject)
+	weight := 0.2
+	err = analyzer.ApplyConfigFile(&ConfigFile{
+		ConditionWeights:       map[string]float64{"晴": weight},
+		OtherConditionMinShare: &[]float64{-1}[0],
+	})
+	if err == nil || analyzer.cfg.conditionWeights["晴"] != 0.35 {
+		t.Error("配置无效时不应做任何修改")
+	}
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func configTestConditions(condition string) []WeatherCondition {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 6; hour++ {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 22.0,
+			Condition:   condition,
+			Humidity:    70.0,
+			WindSpeed:   3.0,
+		})
+	}
+	return conditions
+}
+
+func TestAnalyzerConfig(t *testing.T) {
+	weights := map[string]float64{"大太阳": 0.3}
+	cfg, err := NewAnalyzerConfig(
+		WithConditionWeights(weights),
+		WithDominanceStrategyName("duration"),
+		WithMaxOtherConditions(2),
+	)
+	if err != nil {
+		t.Fatalf("创建分析器配置失败: %v", err)
+	}
+	weights["大太阳"] = 0.9
+	if w := cfg.ConditionWeights()["大太阳"]; w != 0.3 {
+		t.Errorf("配置不应受传入映射表后续修改的影响，期望 0.3，实际 %.2f", w)
+	}
+	if cfg.DominanceStrategy().Name() != "duration" {
+		t.Errorf("主导天气策略错误，期望 duration，实际 %s", cfg.DominanceStrategy().Name())
+	}
+	cfg.ConditionWeights()["晴"] = 0
+	if cfg.ConditionWeights()["晴"] != 0.35 {
+		t.Error("修改权重副本不应影响配置")
+	}
+
+	derived, err := cfg.With(WithConditionWeights(map[string]float64{"晴": 0.6}))
+	if err != nil {
+		t.Fatalf("派生分析器配置失败: %v", err)
+	}
+	if derived.ConditionWeights()["晴"] != 0.6 || cfg.ConditionWeights()["晴"] != 0.35 {
+		t.Error("派生配置不应影响原配置")
+	}
+
+	_, err = NewAnalyzerConfig(WithDominanceStrategyName("unknown"))
+	if !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("未注册的主导天气策略应返回 ErrInvalidInput，实际 %v", err)
+	}
+}
+
+func TestAnalyzerConcurrent(t *testing.T) {
+	cfg := defaultAnalyzerConfig()
+	analyzer := NewAnalyzer(cfg)
+	inputs := []string{"晴", "多云", "小雨", "阴"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	// 对传入的配置及 Config() 返回的副本应用选项不影响分析器
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = WithConditionWeights(map[string]float64{"晴": 0.1})(cfg)
+		_ = WithConditionWeights(map[string]float64{"多云": 0.1})(analyzer.Config())
+	}()
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(condition string) {
+			defer wg.Done()
+			result, err := analyzer.Analyze(context.Background(), configTestConditions(condition))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if result.DominantCondition != condition {
+				errs <- fmt.Errorf("主导天气状况错误，期望 %s，实际 %s", condition, result.DominantCondition)
+			}
+		}(inputs[i%len(inputs)])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if weights := analyzer.Config().ConditionWeights(); weights["晴"] != 0.35 || weights["多云"] != 0.55 {
+		t.Errorf("分析器配置被修改: 晴 %g，多云 %g", weights["晴"], weights["多云"])
+	}
+}
+
+func TestAnalyzerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewAnalyzer(nil).Analyze(ctx, configTestConditions("晴"))
+	if !errors.Is(err, utils.ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后应返回 ErrCanceled 并包装 context.Canceled，实际 %v", err)
+	}
+
+	_, err = NewAnalyzer(nil).Analyze(context.Background(), nil)
+	if !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("数据为空时应返回 ErrInvalidInput，实际 %v", err)
+	}
+}
+
+func TestSettersUseOptions(t *testing.T) {
+	wa, err := NewWeatherAnalyzer(configTestConditions("晴"))
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	if err := wa.SetOtherConditionMinShare(150); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("无效得分占比应返回 ErrInvalidConfig，实际 %v", err)
+	}
+	if err := wa.SetMaxOtherConditions(-1); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("无效数量应返回 ErrInvalidConfig，实际 %v", err)
+	}
+	if err := wa.SetDominanceStrategyByName("unknown"); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("未注册的主导天气策略应返回 ErrInvalidInput，实际 %v", err)
+	}
+	if wa.cfg.otherConditionMinShare != 20 || wa.cfg.maxOtherConditions != 0 || wa.cfg.dominanceStrategy.Name() != "weighted" {
+		t.Error("设置失败时不应修改配置")
+	}
+	wa.SetLocation(nil)
+	if wa.cfg.location != time.Local {
+		t.Errorf("时区为 nil 时应使用 time.Local，实际 %v", wa.cfg.location)
+	}
+}
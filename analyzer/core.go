@@ -1,7 +1,11 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"time"
+
 	"github.com/louismax/weather_analyzer/utils"
 )
 
@@ -19,57 +23,158 @@ type WeatherCondition struct {
 	WindSpeed float64
 	// Precipitation 表示降水量，单位为毫米
 	Precipitation float64
+	// DewPoint 表示露点温度，单位为摄氏度，可选
+	DewPoint *float64
+	// WindDirection 表示风向，单位为度，0为北风，顺时针增加，可选，可由 ParseWindDirection 解析
+	WindDirection *float64
 }
 
-// WeatherAnalyzer 天气分析器
+// WeatherAnalyzer 天气分析器，绑定一组天气数据并持有独立的配置副本，配置可通过 Set* 方法修改
+// 需要复用配置分析多组数据或并发分析时请使用 Analyzer
 type WeatherAnalyzer struct {
 	conditions []WeatherCondition
-	// 天气状况权重映射
-	conditionWeights map[string]float64
-	// 降水量阈值（毫米/小时）
-	precipitationThresholds map[string]float64
-	// 风速阈值（米/秒）
-	windSpeedThresholds map[string]float64
+	// 分析器配置，为本分析器独有
+	cfg *AnalyzerConfig
 }
 
 // WeatherAnalysisResult 天气分析结果
 type WeatherAnalysisResult struct {
 	// 主要天气状况
 	DominantCondition string
-	// 其他重要天气状况
-	OtherConditions []string
+	// 主要天气状况的置信度(0-1)，由领先第二名的幅度及数据完整性计算
+	Confidence float64
+	// 主要天气大类，开启大类汇总时为加权时长最大的大类，否则为主要天气状况所属大类
+	DominantFamily WeatherFamily
+	// 各天气大类的加权时长汇总
+	FamilyWeights map[WeatherFamily]float64
+	// 其他重要天气状况，按得分由高到低排列
+	OtherConditions []RankedCondition
 	// 平均温度（摄氏度）
 	AverageTemperature float64
+	// 最低温度（摄氏度）
+	MinTemperature float64
+	// 最低温度出现时间，无观测时间时为零值
+	MinTemperatureTime time.Time
+	// 最低温度所在记录序号（从1开始），缺测填补记录为0
+	MinTemperatureIndex int
+	// 最高温度（摄氏度）
+	MaxTemperature float64
+	// 最高温度出现时间，无观测时间时为零值
+	MaxTemperatureTime time.Time
+	// 最高温度所在记录序号（从1开始），缺测填补记录为0
+	MaxTemperatureIndex int
+	// 温度日较差，即最高温度与最低温度之差（摄氏度）
+	TemperatureRange float64
+	// 温度标准差（摄氏度），按记录代表的时长加权
+	TemperatureStdDev float64
+	// 最大小时温度变化（摄氏度），负值表示降温
+	MaxHourlyTemperatureChange float64
+	// 最大小时温度变化出现时间，无观测时间时为零值
+	MaxHourlyTemperatureChangeTime time.Time
+	// 平均露点（摄氏度），按记录代表的时长加权
+	AverageDewPoint float64
+	// 平均体感温度（摄氏度），按记录代表的时长加权
+	AverageApparentTemperature float64
+	// 最低体感温度（摄氏度）
+	MinApparentTemperature float64
+	// 最高体感温度（摄氏度）
+	MaxApparentTemperature float64
+	// 最大炎热指数（摄氏度）
+	MaxHeatIndex float64
+	// 最大炎热指数出现时间，无观测时间时为零值
+	MaxHeatIndexTime time.Time
+	// 最低风寒温度（摄氏度）
+	MinWindChill float64
+	// 最低风寒温度出现时间，无观测时间时为零值
+	MinWindChillTime time.Time
+	// 按体感指标推导的“热”“冷”天气持续时长，默认仅作体感标注，ComfortThresholds.Scored 开启时同时计入 ConditionDurations 及主导天气判断
+	ComfortConditions map[string]time.Duration
 	// 总降水量（毫米）
 	TotalPrecipitation float64
 	// 最大小时降水量（毫米）
 	MaxPrecipitation float64
-	// 降水持续小时数
+	// 最大12小时降水量（毫米）
+	MaxPrecipitation12h float64
+	// 最大24小时降水量（毫米）
+	MaxPrecipitation24h float64
+	// 最强降水强度等级，如 暴雨、大雪，无降水时为空
+	PrecipitationIntensity string
+	// 降水持续小时数（按降水持续时长四舍五入）
 	PrecipitationHours int
+	// 降水持续时长
+	PrecipitationDuration time.Duration
 	// 平均风速（米/秒）
 	AverageWindSpeed float64
 	// 最大风速（米/秒）
 	MaxWindSpeed float64
-	// 天气状况权重统计
+	// 最小风力等级，不含静风及阵风
+	MinWindForce int
+	// 最大风力等级，不含阵风
+	MaxWindForce int
+	// 盛行风向(8方位)，如 东南，没有风向数据时为空
+	PrevailingWindDirection string
+	// 风玫瑰图，没有风向数据时为 nil
+	WindRose *WindRose
+	// 阵风
+	Gusts []WindGust
+	// 天气状况得分，按主导天气策略计算，默认为权重×持续小时数
 	ConditionWeights map[string]float64
+	// 主导天气策略名称
+	DominanceStrategy string
+	// 各天气状况统计，按首次出现的次序排列
+	ConditionStats []ConditionStats
+	// 主导天气判断过程，仅在 SetExplain(true) 时给出
+	Explanation *Explanation
+	// 各天气状况持续时长
+	ConditionDurations map[string]time.Duration
 	// 天气描述文本
 	Description string
+	// 被跳过的无效记录序号（从1开始）
+	SkippedRecords []int
+	// 数值被截断到有效范围的记录序号（从1开始）
+	ClampedRecords []int
+	// 数据校验发现的全部问题
+	ValidationIssues []ValidationIssue
+	// 数据质量标记
+	QualityFlags []ValidationIssue
+	// 数据起始观测时间，存在无法解析的观测时间时为零值
+	StartTime time.Time
+	// 数据结束观测时间，存在无法解析的观测时间时为零值
+	EndTime time.Time
+	// 数据覆盖的时间跨度
+	TimeSpan time.Duration
+	// 与前面记录观测时间重复的记录序号（从1开始）
+	DuplicateRecords []int
+	// 参与分析的记录，观测时间均有效时按时间排序
+	Records []AnalyzedRecord
+	// 期望的观测间隔，无法解析观测时间时为0
+	ExpectedInterval time.Duration
+	// 数据完整性（%），按期望间隔计算实际记录占期望记录的比例
+	Completeness float64
+	// 缺测时段
+	Gaps []DataGap
+	// 缺测填补生成的记录条数
+	FilledRecords int
+	// 天气状况由数值推断得出的记录序号（从1开始）
+	InferredRecords []int
+	// 无法识别(没有权重)的天气状况，不参与主导天气判断
+	UnknownConditions []string
+	// 配置警告，如为没有权重的天气状况设置的阈值或别名不会生效
+	ConfigWarnings []string
+	// 天气状况按实测降水强度升级的记录序号（从1开始）
+	UpgradedRecords []int
+	// 按时间顺序排列的天气段，短暂变化已并入相邻天气段
+	Episodes []ConditionEpisode
+	// 天气转变
+	Transitions []ConditionTransition
+	// 天气转变描述，如“晴转多云”，没有转变或转变过多时为空
+	TransitionText string
 }
 
-// NewWeatherAnalyzer 创建新的天气分析器
+// NewWeatherAnalyzer 创建新的天气分析器，存在无效记录时直接返回错误
 func NewWeatherAnalyzer(conditions []WeatherCondition) (*WeatherAnalyzer, error) {
-	if conditions == nil {
-		return nil, &utils.WeatherError{
-			Code:    utils.ErrInvalidInput,
-			Message: "天气数据不能为空",
-		}
-	}
-
-	if len(conditions) == 0 {
-		return nil, &utils.WeatherError{
-			Code:    utils.ErrEmptyData,
-			Message: "天气数据列表为空",
-		}
+	if err := checkConditions(conditions); err != nil {
+		return nil, err
 	}
 
 	// 验证每个天气条件
@@ -78,324 +183,593 @@ func NewWeatherAnalyzer(conditions []WeatherCondition) (*WeatherAnalyzer, error)
 			return nil, &utils.WeatherError{
 				Code:    utils.ErrInvalidInput,
 				Message: fmt.Sprintf("第%d个天气数据无效", i+1),
+				Field:   err.Field,
+				Value:   err.Value,
+				Index:   i + 1,
 				Err:     err,
 			}
 		}
 	}
 
-	// 初始化天气状况权重
-	// 权重设置原则：
-	// 1. 极端天气（如暴雨、强雷阵雨等）权重最高
-	// 2. 伴有特殊现象（如冰雹、雷电）的天气权重较高
-	// 3. 降水强度大的天气权重较高
-	// 4. 持续时间长的天气权重较高
-	weights := map[string]float64{
-		// 极端天气（权重 0.95-1.00）
-		"特大暴雨":    1.00,  // 最强降水，持续时间长，影响范围大
-		"大暴雨":     0.995, // 极强降水，持续时间长
-		"极端降雨":    0.99,  // 极端降水，影响范围大
-		"雷阵雨伴有冰雹": 0.98,  // 伴有冰雹，危险性极高
-		"强雷阵雨":    0.97,  // 强降水+雷电，危险性高
-		"强阵雨":     0.96,  // 强降水，持续时间短
-		"暴雨":      0.955, // 强降水，持续时间长
-		"暴雪":      0.95,  // 强降雪，持续时间长，影响大
-		"强沙尘暴":    0.93,  // 极强沙尘，能见度极低
-		"特强浓雾":    0.92,  // 能见度极低，影响极大
-
-		// 较强天气（权重 0.85-0.94）
-		"雷阵雨": 0.92, // 降水+雷电，危险性较高
-		"大雨":  0.90, // 较强降水，持续时间长
-		"中雨":  0.88, // 中等降水，持续时间长
-		"冻雨":  0.87, // 特殊降水，易造成道路结冰
-		"大雪":  0.86, // 较强降雪，持续时间长
-		"沙尘暴": 0.85, // 强沙尘，能见度低
-		"强浓雾": 0.83, // 能见度很低
-		"严重霾": 0.81, // 空气质量极差
-
-		// 过渡性天气（权重 0.80-0.89）
-		"大暴雨到特大暴雨": 0.89, // 极强降水过渡
-		"暴雨到大暴雨":   0.87, // 强降水过渡
-		"大到暴雨":     0.85, // 较强降水过渡
-		"中到大雨":     0.83, // 中等降水过渡
-		"小到中雨":     0.81, // 弱降水过渡
-		"大到暴雪":     0.84, // 较强降雪过渡
-		"中到大雪":     0.82, // 中等降雪过渡
-		"小到中雪":     0.80, // 弱降雪过渡
-
-		// 一般天气（权重 0.70-0.84）
-		"阵雨":     0.82, // 短时降水
-		"雨":      0.80, // 基础降水天气
-		"小雨":     0.78, // 弱降水，持续时间长
-		"毛毛雨/细雨": 0.75, // 极弱降水，持续时间长
-		"中雪":     0.77, // 中等降雪
-		"小雪":     0.76, // 弱降雪
-		"雪":      0.75, // 基础降雪天气
-		"雨夹雪":    0.74, // 雨雪混合
-		"雨雪天气":   0.73, // 雨雪交替
-		"阵雨夹雪":   0.72, // 短时雨雪混合
-		"阵雪":     0.71, // 短时降雪
-		"重度霾":    0.79, // 空气质量很差
-		"中度霾":    0.78, // 空气质量较差
-		"大雾":     0.77, // 能见度低
-		"浓雾":     0.76, // 能见度较低
-		"雾":      0.75, // 基础雾天气
-		"薄雾":     0.74, // 轻微雾
-		"扬沙":     0.73, // 沙尘天气
-		"浮尘":     0.72, // 轻微沙尘
-		"霾":      0.71, // 基础霾天气
-
-		// 无降水天气（权重 0.30-0.69）
-		"阴":    0.65, // 云量多，影响光照
-		"多云":   0.55, // 云量中等
-		"晴间多云": 0.45, // 以晴为主，少量云
-		"少云":   0.40, // 云量少
-		"晴":    0.35, // 无云或少云
-		"热":    0.50, // 高温天气
-		"冷":    0.45, // 低温天气
-	}
-
-	// 初始化降水量阈值
-	precipitationThresholds := map[string]float64{
-		"特大暴雨": 100.0, // 24小时降水量≥100mm
-		"大暴雨":  70.0,  // 24小时降水量≥70mm
-		"暴雨":   50.0,  // 24小时降水量≥50mm
-		"大雨":   25.0,  // 24小时降水量≥25mm
-		"中雨":   10.0,  // 24小时降水量≥10mm
-		"小雨":   0.1,   // 24小时降水量≥0.1mm
-		"毛毛雨":  0.0,   // 24小时降水量>0mm
-	}
-
-	// 初始化风速阈值
-	windSpeedThresholds := map[string]float64{
-		"强沙尘暴": 20.8, // 风速≥20.8m/s（8级风）
-		"沙尘暴":  17.2, // 风速≥17.2m/s（7级风）
-		"扬沙":   10.8, // 风速≥10.8m/s（5级风）
-		"浮尘":   5.5,  // 风速≥5.5m/s（3级风）
-	}
-
-	return &WeatherAnalyzer{
-		conditions:              conditions,
-		conditionWeights:        weights,
-		precipitationThresholds: precipitationThresholds,
-		windSpeedThresholds:     windSpeedThresholds,
-	}, nil
-}
-
-// SetCustomWeights 设置自定义权重
-func (wa *WeatherAnalyzer) SetCustomWeights(customWeights map[string]float64) {
-	// 如果传入了自定义权重，则覆盖默认权重
-	if customWeights != nil {
-		for condition, weight := range customWeights {
-			if _, exists := wa.conditionWeights[condition]; exists {
-				utils.PrintWarnLog(fmt.Sprintf("覆盖默认天气状况权重 '%s' (原值: %.1f, 新值: %.1f)", condition, wa.windSpeedThresholds[condition], weight))
-			} else {
-				utils.PrintInfoLog(fmt.Sprintf("新增天气状况权重 '%s' (值: %.1f)", condition, weight))
-			}
-			wa.conditionWeights[condition] = weight
+	return newWeatherAnalyzer(conditions, InvalidRecordReject), nil
+}
+
+// NewWeatherAnalyzerWithPolicy 创建新的天气分析器(指定无效记录处理策略)
+// 数据校验推迟到 Analyze 时进行，跳过或截断的记录会在分析结果中报告
+func NewWeatherAnalyzerWithPolicy(conditions []WeatherCondition, policy InvalidRecordPolicy) (*WeatherAnalyzer, error) {
+	if err := checkConditions(conditions); err != nil {
+		return nil, err
+	}
+	return newWeatherAnalyzer(conditions, policy), nil
+}
+
+// checkConditions 检查天气数据是否为空
+func checkConditions(conditions []WeatherCondition) error {
+	if conditions == nil {
+		return &utils.WeatherError{
+			Code:    utils.ErrInvalidInput,
+			Message: "天气数据不能为空",
 		}
 	}
+
+	if len(conditions) == 0 {
+		return &utils.WeatherError{
+			Code:    utils.ErrEmptyData,
+			Message: "天气数据列表为空",
+		}
+	}
+	return nil
+}
+
+// newWeatherAnalyzer 使用默认配置创建天气分析器
+func newWeatherAnalyzer(conditions []WeatherCondition, policy InvalidRecordPolicy) *WeatherAnalyzer {
+	cfg := defaultAnalyzerConfig()
+	cfg.invalidRecordPolicy = policy
+	return &WeatherAnalyzer{conditions: conditions, cfg: cfg}
+}
+
+// defaultConditionWeights 默认天气状况权重，取自内置默认配置 defaults.json
+// 权重设置原则：极端天气及伴有冰雹、雷电等特殊现象的天气权重较高，降水强度越大权重越高
+func defaultConditionWeights() map[string]float64 {
+	return copyMap(embeddedDefaults.ConditionWeights)
+}
+
+// defaultPrecipitationThresholds 默认降水量阈值，取自内置默认配置 defaults.json
+func defaultPrecipitationThresholds() map[string]float64 {
+	return copyMap(embeddedDefaults.PrecipitationThresholds)
+}
+
+// defaultWindSpeedThresholds 默认风速阈值（米/秒），取自内置默认配置 defaults.json
+func defaultWindSpeedThresholds() map[string]float64 {
+	return copyMap(embeddedDefaults.WindSpeedThresholds)
+}
+
+// SetLogger 设置分析器日志，传入 nil 时使用全局日志
+func (wa *WeatherAnalyzer) SetLogger(l utils.Logger) {
+	wa.set(WithLogger(l))
+}
+
+// SetValidationRules 设置数据校验规则，如针对极地、热带站点放宽或收紧数值范围，应从 DefaultValidationRules() 开始修改
+// 规则不完整(如上限为0)时返回 ErrInvalidConfig 错误且不做任何修改
+// 通过 NewWeatherAnalyzer 创建的分析器在创建时已按默认规则校验
+func (wa *WeatherAnalyzer) SetValidationRules(rules ValidationRules) error {
+	return wa.apply(WithValidationRules(rules))
+}
+
+// SetLocation 设置解析不含时区偏移的观测时间所用时区，默认为 time.Local
+func (wa *WeatherAnalyzer) SetLocation(loc *time.Location) {
+	wa.set(WithLocation(loc))
+}
+
+// SetDuplicatePolicy 设置重复时间记录处理策略，默认仅报告不处理
+func (wa *WeatherAnalyzer) SetDuplicatePolicy(policy DuplicatePolicy) {
+	wa.set(WithDuplicatePolicy(policy))
+}
+
+// SetExpectedInterval 设置期望的观测间隔，用于检测缺测，为0时取相邻记录间隔的中位数
+func (wa *WeatherAnalyzer) SetExpectedInterval(interval time.Duration) {
+	wa.set(WithExpectedInterval(interval))
+}
+
+// SetExpectedPeriod 设置期望的观测时间范围(首尾均包含)，用于检测数据首尾的缺测
+func (wa *WeatherAnalyzer) SetExpectedPeriod(start, end time.Time) {
+	wa.set(WithExpectedPeriod(start, end))
+}
+
+// SetGapFill 设置缺测填补选项，默认不填补
+func (wa *WeatherAnalyzer) SetGapFill(opts GapFillOptions) {
+	wa.set(WithGapFill(opts))
+}
+
+// SetTransitionMinPersistence 设置天气转变的最短持续时长，默认2小时，更短的天气变化并入相邻天气段
+func (wa *WeatherAnalyzer) SetTransitionMinPersistence(d time.Duration) {
+	wa.set(WithTransitionMinPersistence(d))
+}
+
+// SetIntensityMismatchPolicy 设置天气状况与实测降水强度不符时的处理策略，默认仅标记
+func (wa *WeatherAnalyzer) SetIntensityMismatchPolicy(policy IntensityMismatchPolicy) {
+	wa.set(WithIntensityMismatchPolicy(policy))
+}
+
+// SetGustThreshold 设置阵风判定阈值（米/秒），默认5米/秒，为0时不检测阵风
+func (wa *WeatherAnalyzer) SetGustThreshold(threshold float64) {
+	wa.set(WithGustThreshold(threshold))
+}
+
+// SetOtherConditionMinShare 设置其他重要天气状况的最低得分占比（%），默认20%，超出0-100时返回 ErrInvalidConfig 错误
+func (wa *WeatherAnalyzer) SetOtherConditionMinShare(share float64) error {
+	return wa.apply(WithOtherConditionMinShare(share))
+}
+
+// SetMaxOtherConditions 设置其他重要天气状况的最大数量，默认为0即不限，小于0时返回 ErrInvalidConfig 错误
+func (wa *WeatherAnalyzer) SetMaxOtherConditions(n int) error {
+	return wa.apply(WithMaxOtherConditions(n))
+}
+
+// SetComfortThresholds 设置按体感指标推导“热”“冷”天气的阈值，Enabled 为 false 时不推导，Scored 为 true 时推导结果参与主导天气判断
+func (wa *WeatherAnalyzer) SetComfortThresholds(th ComfortThresholds) {
+	wa.set(WithComfortThresholds(th))
+}
+
+// SetInferenceMode 设置按数值推断天气状况的方式，默认仅推断天气状况为空或“未知”的记录
+func (wa *WeatherAnalyzer) SetInferenceMode(mode InferenceMode) {
+	wa.set(WithInferenceMode(mode))
+}
+
+// SetFamilyAggregation 设置是否先按天气大类(雨、雪、雾等)汇总判断主导天气，再逐级细化到子类及具体天气状况，默认关闭
+func (wa *WeatherAnalyzer) SetFamilyAggregation(enabled bool) {
+	wa.set(WithFamilyAggregation(enabled))
+}
+
+// SetDominanceStrategy 设置主导天气策略，默认为加权时长策略，传入 nil 时恢复默认
+func (wa *WeatherAnalyzer) SetDominanceStrategy(strategy DominanceStrategy) {
+	wa.set(WithDominanceStrategy(strategy))
+}
+
+// SetDominanceStrategyByName 按名称设置已注册的主导天气策略，如 weighted、duration、severity-first
+func (wa *WeatherAnalyzer) SetDominanceStrategyByName(name string) error {
+	return wa.apply(WithDominanceStrategyName(name))
+}
+
+// SetCustomWeights 设置自定义权重，权重应在0-1之间，存在无效权重时返回 ErrInvalidConfig 错误且不做任何修改
+func (wa *WeatherAnalyzer) SetCustomWeights(customWeights map[string]float64) error {
+	before := pickMap(wa.cfg.conditionWeights, customWeights)
+	if err := wa.apply(WithConditionWeights(customWeights)); err != nil {
+		return err
+	}
+	logOverrides(wa.cfg.log(), "天气状况权重", before, customWeights)
+	return nil
 }
 
 // SetCustomPrecipitationThresholds 设置自定义降水量阈值
-func (wa *WeatherAnalyzer) SetCustomPrecipitationThresholds(customPrecipitationThresholds map[string]float64) {
-	// 如果传入了自定义降水量阈值，则覆盖默认阈值
-	if customPrecipitationThresholds != nil {
-		for condition, threshold := range customPrecipitationThresholds {
-			if _, exists := wa.precipitationThresholds[condition]; exists {
-				utils.PrintWarnLog(fmt.Sprintf("覆盖默认降水量阈值 '%s' (原值: %.1f, 新值: %.1f)", condition, wa.precipitationThresholds[condition], threshold))
-			} else {
-				utils.PrintInfoLog(fmt.Sprintf("新增降水量阈值 '%s' (值: %.1f)", condition, threshold))
-			}
-			wa.precipitationThresholds[condition] = threshold
-		}
+// 阈值不能为负数，合并后同一强度序列的阈值应严格递增（如 暴雨 < 大暴雨 < 特大暴雨），否则返回 ErrInvalidConfig 错误且不做任何修改
+func (wa *WeatherAnalyzer) SetCustomPrecipitationThresholds(customPrecipitationThresholds map[string]float64) error {
+	before := pickMap(wa.cfg.precipitationThresholds, customPrecipitationThresholds)
+	if err := wa.apply(WithPrecipitationThresholds(customPrecipitationThresholds)); err != nil {
+		return err
 	}
+	logOverrides(wa.cfg.log(), "降水量阈值", before, customPrecipitationThresholds)
+	wa.warnUnweighted("降水量阈值", customPrecipitationThresholds)
+	return nil
 }
 
 // SetCustomWindSpeedThresholds 设置自定义风速阈值
-func (wa *WeatherAnalyzer) SetCustomWindSpeedThresholds(customWindSpeedThresholds map[string]float64) {
-	// 如果传入了自定义风速阈值，则覆盖默认阈值
-	if customWindSpeedThresholds != nil {
-		for condition, threshold := range customWindSpeedThresholds {
-			if _, exists := wa.windSpeedThresholds[condition]; exists {
-				utils.PrintWarnLog(fmt.Sprintf("覆盖默认风速阈值 '%s' (原值: %.1f, 新值: %.1f)", condition, wa.windSpeedThresholds[condition], threshold))
-			} else {
-				utils.PrintInfoLog(fmt.Sprintf("新增风速阈值 '%s' (值: %.1f)", condition, threshold))
-			}
-			wa.windSpeedThresholds[condition] = threshold
-		}
+// 阈值不能为负数，合并后同一强度序列的阈值应严格递增（如 扬沙 < 沙尘暴 < 强沙尘暴），否则返回 ErrInvalidConfig 错误且不做任何修改
+func (wa *WeatherAnalyzer) SetCustomWindSpeedThresholds(customWindSpeedThresholds map[string]float64) error {
+	before := pickMap(wa.cfg.windSpeedThresholds, customWindSpeedThresholds)
+	if err := wa.apply(WithWindSpeedThresholds(customWindSpeedThresholds)); err != nil {
+		return err
 	}
+	logOverrides(wa.cfg.log(), "风速阈值", before, customWindSpeedThresholds)
+	wa.warnUnweighted("风速阈值", customWindSpeedThresholds)
+	return nil
 }
 
-// validateWeatherCondition 验证天气条件数据
-func validateWeatherCondition(c WeatherCondition) error {
-	if c.Temperature < -100 || c.Temperature > 100 {
-		return &utils.WeatherError{
-			Code:    utils.ErrInvalidTemperature,
-			Message: fmt.Sprintf("温度数据异常: %.1f°C", c.Temperature),
-		}
-	}
+// apply 对分析器配置应用选项，Set* 方法均通过对应的 With* 选项实现
+// 选项可能校验失败的 Set* 方法返回该错误，如 SetCustomWeights、SetValidationRules
+func (wa *WeatherAnalyzer) apply(opt Option) error {
+	return opt(wa.cfg)
+}
 
-	if c.Humidity < 0 || c.Humidity > 100 {
-		return &utils.WeatherError{
-			Code:    utils.ErrInvalidHumidity,
-			Message: fmt.Sprintf("湿度数据异常: %.1f%%", c.Humidity),
+// set 应用不会失败的选项，供不返回错误的 Set* 方法使用
+// 这些选项(如 WithLogger、WithGapFill)只赋值不校验，始终返回 nil；为选项增加校验时应改用 apply 并由 Set* 方法返回错误
+func (wa *WeatherAnalyzer) set(opt Option) {
+	_ = opt(wa.cfg)
+}
+
+// warnUnweighted 提示没有权重的天气状况的阈值不会生效
+func (wa *WeatherAnalyzer) warnUnweighted(kind string, thresholds map[string]float64) {
+	for _, condition := range sortedKeys(thresholds) {
+		if _, ok := wa.cfg.conditionWeights[condition]; !ok {
+			wa.cfg.log().Warn(kind+"对应的天气状况没有权重，阈值不会生效", "condition", condition)
 		}
 	}
+}
 
-	if c.WindSpeed < 0 || c.WindSpeed > 100 {
-		return &utils.WeatherError{
-			Code:    utils.ErrInvalidWindSpeed,
-			Message: fmt.Sprintf("风速数据异常: %.1f m/s", c.WindSpeed),
+// pickMap 取 m 中与 keys 同名的项
+func pickMap[V, K any](m map[string]V, keys map[string]K) map[string]V {
+	picked := make(map[string]V, len(keys))
+	for k := range keys {
+		if v, ok := m[k]; ok {
+			picked[k] = v
 		}
 	}
+	return picked
+}
 
-	if c.Precipitation < 0 || c.Precipitation > 1000 {
-		return &utils.WeatherError{
-			Code:    utils.ErrInvalidPrecipitation,
-			Message: fmt.Sprintf("降水量数据异常: %.1f mm", c.Precipitation),
+// logOverrides 记录配置项的覆盖与新增，before 为覆盖前的同名项
+func logOverrides[V any](log utils.Logger, kind string, before, values map[string]V) {
+	for _, name := range sortedKeys(values) {
+		if old, exists := before[name]; exists {
+			log.Warn("覆盖默认"+kind, "condition", name, "old_value", old, "new_value", values[name])
+		} else {
+			log.Info("新增"+kind, "condition", name, "value", values[name])
 		}
 	}
-
-	return nil
 }
 
 // Analyze 分析天气状况并返回分析结果
 func (wa *WeatherAnalyzer) Analyze() (*WeatherAnalysisResult, error) {
-	if len(wa.conditions) == 0 {
+	return wa.analyzer().Analyze(context.Background(), wa.conditions)
+}
+
+// analyzer 以本分析器的配置创建 Analyzer
+func (wa *WeatherAnalyzer) analyzer() *Analyzer {
+	return &Analyzer{cfg: wa.cfg}
+}
+
+// analysisData 预处理后的分析数据
+type analysisData struct {
+	*timeline
+	outcome      validationOutcome
+	completeness completeness
+	// 天气状况按实测降水强度升级的记录序号
+	upgraded []int
+}
+
+// prepare 校验数据、处理无效记录并按时间整理记录
+func (a *Analyzer) prepare(ctx context.Context, conditions []WeatherCondition) (*analysisData, error) {
+	if len(conditions) == 0 {
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrEmptyData,
 			Message: "没有可分析的天气数据",
 		}
 	}
 
-	// 计算总降水量和平均降水量
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// 校验数据并按策略处理无效记录
+	records, outcome, err := applyInvalidRecordPolicy(conditions, a.cfg.invalidRecordPolicy, a.cfg.validationRules, a.normalizedCondition)
+	if err != nil {
+		return nil, err
+	}
+
+	// 规范化天气状况文本，并按数值推断缺少的天气状况
+	a.normalizeConditions(records)
+	outcome.report.addFlags(inferConditions(records, a.cfg.inferenceMode, a.cfg.windSpeedThresholds)...)
+
+	// 解析观测时间、排序并处理重复记录
+	tl := newTimeline(records, a.cfg.location)
+	tl.dedupe(a.cfg.duplicatePolicy, a.cfg.conditionWeights)
+	outcome.report.addFlags(tl.flags...)
+	outcome.report.addFlags(temporalFlags(tl.records, tl.timed, a.cfg.validationRules)...)
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// 检测并按需填补缺测
+	completeness := tl.detectGaps(a.cfg.expectedInterval, a.cfg.expectedStart, a.cfg.expectedEnd)
+	tl.fillGaps(completeness, a.cfg.gapFill)
+	tl.assignDurations(completeness.interval)
+
+	// 划分风力等级并计算体感指标
+	for i := range tl.records {
+		tl.records[i].WindForce = BeaufortForce(tl.records[i].WindSpeed)
+		tl.records[i].Comfort = thermalComfort(tl.records[i].WeatherCondition)
+	}
+
+	// 划分降水强度等级并检查天气状况是否与之相符
+	intensityFlags, upgraded := classifyIntensity(tl.records, tl.timed, a.cfg.intensityMismatchPolicy, a.cfg.conditionWeights)
+	outcome.report.addFlags(intensityFlags...)
+	refineInferred(tl.records, a.cfg.conditionWeights)
+
+	return &analysisData{timeline: tl, outcome: outcome, completeness: completeness, upgraded: upgraded}, nil
+}
+
+// analyze 对预处理后的数据进行分析，period 为描述文本中的时段名称，如 今日、白天
+func (a *Analyzer) analyze(data *analysisData, period string) *WeatherAnalysisResult {
+	conditions := data.records
+
+	// 计算总时长，每条记录按其代表的时长加权
+	var totalHours float64
+	for _, c := range conditions {
+		totalHours += c.Duration.Hours()
+	}
+
+	// 计算总降水量、最大小时降水量和降水持续时长
 	var totalPrecipitation float64
-	var maxPrecipitation float64
-	var precipitationHours int
-	for _, c := range wa.conditions {
+	var precipitationDuration time.Duration
+	for _, c := range conditions {
 		totalPrecipitation += c.Precipitation
-		if c.Precipitation > maxPrecipitation {
-			maxPrecipitation = c.Precipitation
-		}
 		if c.Precipitation > 0 {
-			precipitationHours++
+			precipitationDuration += c.Duration
 		}
 	}
+	maxPrecipitation := maxHourlyPrecipitation(conditions, data.timed)
+	maxPrecipitation12h := maxWindowPrecipitation(conditions, data.timed, 12*time.Hour)
+	maxPrecipitation24h := maxWindowPrecipitation(conditions, data.timed, 24*time.Hour)
+	precipitationIntensity := overallIntensity(conditions, data.timed)
+	precipitationHours := int(math.Round(precipitationDuration.Hours()))
 
 	// 计算平均风速和最大风速
 	var totalWindSpeed float64
 	var maxWindSpeed float64
-	for _, c := range wa.conditions {
-		totalWindSpeed += c.WindSpeed
+	for _, c := range conditions {
+		totalWindSpeed += c.WindSpeed * c.Duration.Hours()
 		if c.WindSpeed > maxWindSpeed {
 			maxWindSpeed = c.WindSpeed
 		}
 	}
-	avgWindSpeed := totalWindSpeed / float64(len(wa.conditions))
+	avgWindSpeed := totalWindSpeed / totalHours
+	wind := analyzeWind(conditions, a.cfg.gustThreshold)
 
-	// 根据降水量和风速调整天气状况权重
+	// 根据降水量和风速调整天气状况权重，并记录生效的调整
+	boosts := make(map[string][]WeightBoost)
 	adjustedWeights := make(map[string]float64)
-	for condition, weight := range wa.conditionWeights {
+	for condition, weight := range a.cfg.conditionWeights {
 		adjustedWeights[condition] = weight
 	}
 
-	// 根据降水量调整权重
-	for condition, threshold := range wa.precipitationThresholds {
-		if totalPrecipitation >= threshold {
+	// 根据降水量调整权重，阈值为24小时降水量，按任意连续24小时的最大降水量判断
+	for condition, threshold := range a.cfg.precipitationThresholds {
+		if maxPrecipitation24h > 0 && maxPrecipitation24h >= threshold {
 			// 增加符合降水量条件的天气权重
-			adjustedWeights[condition] *= 1.2
+			adjustedWeights[condition] *= a.cfg.precipitationBoostFactor
+			boosts[condition] = append(boosts[condition], WeightBoost{
+				Source: BoostPrecipitation, Threshold: threshold, Value: maxPrecipitation24h, Factor: a.cfg.precipitationBoostFactor,
+			})
 		}
-
 	}
 
 	// 根据风速调整权重
-	for condition, threshold := range wa.windSpeedThresholds {
+	for condition, threshold := range a.cfg.windSpeedThresholds {
 		if maxWindSpeed >= threshold {
 			// 增加符合风速条件的天气权重
-			adjustedWeights[condition] *= 1.15
+			adjustedWeights[condition] *= a.cfg.windSpeedBoostFactor
+			boosts[condition] = append(boosts[condition], WeightBoost{
+				Source: BoostWindSpeed, Threshold: threshold, Value: maxWindSpeed, Factor: a.cfg.windSpeedBoostFactor,
+			})
 		}
 	}
 
-	// 统计各种天气状况的加权出现次数
-	conditionWeightedCount := make(map[string]float64)
-	for _, c := range wa.conditions {
-		weight := adjustedWeights[c.Condition]
-		conditionWeightedCount[c.Condition] += weight
+	// 统计各种天气状况并按主导天气策略计算得分，空白及无法识别的天气状况不参与
+	conditionStats := buildConditionStats(conditions, a.cfg.conditionWeights, adjustedWeights, a.cfg.comfortThresholds.scoredCondition)
+	rankable := rankableStats(conditionStats, a.cfg.conditionWeights)
+	conditionWeightedCount := a.cfg.dominanceStrategy.Scores(rankable)
+	conditionDurations := make(map[string]time.Duration, len(conditionStats))
+	for _, s := range conditionStats {
+		conditionDurations[s.Condition] = s.Duration
 	}
 
-	// 计算平均温度
+	// 计算时间加权平均温度
 	var totalTemp float64
-	for _, c := range wa.conditions {
-		totalTemp += c.Temperature
+	for _, c := range conditions {
+		totalTemp += c.Temperature * c.Duration.Hours()
 	}
-	avgTemp := totalTemp / float64(len(wa.conditions))
+	avgTemp := totalTemp / totalHours
+	temperature := analyzeTemperature(conditions, data.timed, avgTemp, totalHours)
+	comfort := analyzeComfort(conditions, totalHours, a.cfg.comfortThresholds)
 
-	// 找出加权后出现最多的天气状况
-	var maxWeight float64
+	// 找出得分最高的天气状况，得分相同时按严重程度、持续时长、首次出现次序确定
+	ranked := rankConditions(rankable, conditionWeightedCount)
 	var dominantCondition string
-	for condition, weight := range conditionWeightedCount {
-		if weight > maxWeight {
-			maxWeight = weight
-			dominantCondition = condition
+	if len(ranked) > 0 && conditionWeightedCount[ranked[0].Condition] > 0 {
+		dominantCondition = ranked[0].Condition
+	}
+	class, _ := ClassifyCondition(dominantCondition)
+	dominantFamily := class.Family
+	if a.cfg.familyAggregation {
+		if family, condition := dominantByFamily(conditionWeightedCount, ranked); condition != "" {
+			dominantFamily, dominantCondition = family, condition
 		}
 	}
+	confidence := dominanceConfidence(ranked, conditionWeightedCount, dominantCondition, data.completeness.percent)
 
-	// 生成天气描述
-	description := fmt.Sprintf("今日天气以%s为主，平均温度%.1f°C", dominantCondition, avgTemp)
+	var explanation *Explanation
+	if a.cfg.explain {
+		explanation = explainDominance(a.cfg.dominanceStrategy.Name(), ranked, conditionWeightedCount, boosts, dominantCondition, dominantFamily, a.cfg.familyAggregation)
+	}
+
+	// 无法识别的天气状况，已在分析结果的 UnknownConditions 中给出，仅输出调试日志
+	unknownConditions := a.unknownConditions(conditions)
+	if len(unknownConditions) > 0 {
+		a.cfg.log().Debug("存在无法识别的天气状况", "value", unknownConditions)
+	}
+
+	// 识别天气转变
+	episodes := detectEpisodes(conditions, a.cfg.transitionMinPersistence)
+	transitionText := transitionPhrase(episodes)
+
+	// 生成天气描述，没有主要天气状况(如天气状况均无法识别)时不描述主导天气
+	description := fmt.Sprintf("%s天气以%s为主，平均温度%.1f°C", period, dominantCondition, avgTemp)
+	switch {
+	case dominantCondition == "" && transitionText != "":
+		description = fmt.Sprintf("%s天气%s，平均温度%.1f°C", period, transitionText, avgTemp)
+	case dominantCondition == "":
+		description = fmt.Sprintf("%s平均温度%.1f°C", period, avgTemp)
+	case transitionText != "":
+		description = fmt.Sprintf("%s天气%s，以%s为主，平均温度%.1f°C", period, transitionText, dominantCondition, avgTemp)
+	}
+	description += "，" + temperature.phrase()
 
 	// 添加降水量信息
 	if totalPrecipitation > 0 {
 		description += fmt.Sprintf("，总降水量%.1f毫米", totalPrecipitation)
-		if precipitationHours > 0 {
-			description += fmt.Sprintf("，降水持续%d小时", precipitationHours)
+		if precipitationDuration > 0 {
+			description += fmt.Sprintf("，降水持续%s", formatDuration(precipitationDuration))
 		}
 		if maxPrecipitation > 0 {
 			description += fmt.Sprintf("，最大小时降水量%.1f毫米", maxPrecipitation)
 		}
+		if precipitationIntensity != "" {
+			description += fmt.Sprintf("，降水强度达%s", precipitationIntensity)
+		}
 	}
 
 	// 添加风速信息
 	if maxWindSpeed > 0 {
 		description += fmt.Sprintf("，平均风速%.1f米/秒，最大风速%.1f米/秒", avgWindSpeed, maxWindSpeed)
 	}
-	description += "。"
-
-	// 添加其他重要天气状况（权重超过总权重的20%）
-	var otherConditions []string
-	totalWeight := 0.0
-	for _, weight := range conditionWeightedCount {
-		totalWeight += weight
+	if phrase := wind.phrase(); phrase != "" {
+		description += "，" + phrase
 	}
-	threshold := totalWeight * 0.2
-
-	for condition, weight := range conditionWeightedCount {
-		if condition != dominantCondition && weight >= threshold {
-			otherConditions = append(otherConditions, condition)
+	if len(wind.gusts) > 0 {
+		var strongest WindGust
+		for _, g := range wind.gusts {
+			if g.Speed > strongest.Speed {
+				strongest = g
+			}
 		}
+		description += fmt.Sprintf("，阵风%d级", strongest.Force)
 	}
+	description += "。"
 
+	// 添加其他重要天气状况（得分占比不低于设定值），按得分由高到低排列
+	otherConditions := rankOtherConditions(ranked, conditionWeightedCount, dominantCondition, a.cfg.otherConditionMinShare, a.cfg.maxOtherConditions)
 	if len(otherConditions) > 0 {
 		description += "期间还出现"
-		for i, condition := range otherConditions {
+		for i, other := range otherConditions {
 			if i > 0 {
 				description += "、"
 			}
-			description += condition
+			description += other.Condition
 		}
 		description += "。"
 	}
 
+	// 数据覆盖时间
+	var startTime, endTime time.Time
+	if data.timed {
+		startTime, endTime = conditions[0].At, conditions[len(conditions)-1].At
+	}
+	filledRecords := 0
+	var inferredRecords []int
+	for _, c := range conditions {
+		if c.Filled {
+			filledRecords++
+		}
+		if c.Inferred {
+			inferredRecords = append(inferredRecords, c.Index)
+		}
+	}
+
 	// 返回分析结果
 	return &WeatherAnalysisResult{
-		DominantCondition:  dominantCondition,
-		OtherConditions:    otherConditions,
-		AverageTemperature: avgTemp,
-		TotalPrecipitation: totalPrecipitation,
-		MaxPrecipitation:   maxPrecipitation,
-		PrecipitationHours: precipitationHours,
-		AverageWindSpeed:   avgWindSpeed,
-		MaxWindSpeed:       maxWindSpeed,
-		ConditionWeights:   conditionWeightedCount,
-		Description:        description,
-	}, nil
+		DominantCondition:              dominantCondition,
+		DominantFamily:                 dominantFamily,
+		Confidence:                     confidence,
+		FamilyWeights:                  familyWeights(conditionWeightedCount),
+		OtherConditions:                otherConditions,
+		AverageTemperature:             avgTemp,
+		MinTemperature:                 temperature.min.Temperature,
+		MinTemperatureTime:             temperature.min.At,
+		MinTemperatureIndex:            temperature.min.Index,
+		MaxTemperature:                 temperature.max.Temperature,
+		MaxTemperatureTime:             temperature.max.At,
+		MaxTemperatureIndex:            temperature.max.Index,
+		TemperatureRange:               temperature.max.Temperature - temperature.min.Temperature,
+		TemperatureStdDev:              temperature.stdDev,
+		MaxHourlyTemperatureChange:     temperature.maxChange,
+		MaxHourlyTemperatureChangeTime: temperature.maxChangeAt,
+		AverageDewPoint:                comfort.avgDewPoint,
+		AverageApparentTemperature:     comfort.avgApparent,
+		MinApparentTemperature:         comfort.minApparent,
+		MaxApparentTemperature:         comfort.maxApparent,
+		MaxHeatIndex:                   comfort.maxHeatIndex.Comfort.HeatIndex,
+		MaxHeatIndexTime:               comfort.maxHeatIndex.At,
+		MinWindChill:                   comfort.minWindChill.Comfort.WindChill,
+		MinWindChillTime:               comfort.minWindChill.At,
+		ComfortConditions:              comfort.derived,
+		TotalPrecipitation:             totalPrecipitation,
+		MaxPrecipitation:               maxPrecipitation,
+		MaxPrecipitation12h:            maxPrecipitation12h,
+		MaxPrecipitation24h:            maxPrecipitation24h,
+		PrecipitationIntensity:         precipitationIntensity,
+		PrecipitationHours:             precipitationHours,
+		AverageWindSpeed:               avgWindSpeed,
+		MaxWindSpeed:                   maxWindSpeed,
+		MinWindForce:                   wind.minForce,
+		MaxWindForce:                   wind.maxForce,
+		PrevailingWindDirection:        wind.prevailing,
+		WindRose:                       wind.rose,
+		Gusts:                          wind.gusts,
+		ConditionWeights:               conditionWeightedCount,
+		DominanceStrategy:              a.cfg.dominanceStrategy.Name(),
+		ConditionStats:                 conditionStats,
+		Explanation:                    explanation,
+		ConditionDurations:             conditionDurations,
+		PrecipitationDuration:          precipitationDuration,
+		Description:                    description,
+		SkippedRecords:                 data.outcome.skipped,
+		ClampedRecords:                 data.outcome.clamped,
+		ValidationIssues:               data.outcome.report.Issues,
+		QualityFlags:                   data.outcome.report.Flags,
+		StartTime:                      startTime,
+		EndTime:                        endTime,
+		TimeSpan:                       endTime.Sub(startTime),
+		DuplicateRecords:               data.duplicates,
+		Records:                        conditions,
+		ExpectedInterval:               data.completeness.interval,
+		Completeness:                   data.completeness.percent,
+		Gaps:                           data.completeness.gaps,
+		FilledRecords:                  filledRecords,
+		InferredRecords:                inferredRecords,
+		UnknownConditions:              unknownConditions,
+		ConfigWarnings:                 a.cfg.Warnings(),
+		UpgradedRecords:                data.upgraded,
+		Episodes:                       episodes,
+		Transitions:                    episodeTransitions(episodes),
+		TransitionText:                 transitionText,
+	}
+}
+
+// maxHourlyPrecipitation 计算最大小时降水量，有观测时间时按自然小时累计
+// 记录代表的时长超过1小时时按比例折算为小时降水量，如3小时9毫米计为每小时3毫米
+func maxHourlyPrecipitation(records []AnalyzedRecord, timed bool) float64 {
+	var maxPrecipitation float64
+	hourlyRate := func(c AnalyzedRecord) float64 {
+		if c.Duration > time.Hour {
+			return c.Precipitation * float64(time.Hour) / float64(c.Duration)
+		}
+		return c.Precipitation
+	}
+	if !timed {
+		for _, c := range records {
+			maxPrecipitation = math.Max(maxPrecipitation, hourlyRate(c))
+		}
+		return maxPrecipitation
+	}
+	hourly := make(map[time.Time]float64)
+	for _, c := range records {
+		hour := time.Date(c.At.Year(), c.At.Month(), c.At.Day(), c.At.Hour(), 0, 0, 0, c.At.Location())
+		hourly[hour] += hourlyRate(c)
+		maxPrecipitation = math.Max(maxPrecipitation, hourly[hour])
+	}
+	return maxPrecipitation
+}
+
+// formatDuration 将时长格式化为“X小时Y分钟”
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case minutes == 0:
+		return fmt.Sprintf("%d小时", hours)
+	case hours == 0:
+		return fmt.Sprintf("%d分钟", minutes)
+	}
+	return fmt.Sprintf("%d小时%d分钟", hours, minutes)
 }
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+)
+
+// 权重调整依据
+const (
+	// BoostPrecipitation 按最大24小时降水量调整
+	BoostPrecipitation = "降水量"
+	// BoostWindSpeed 按最大风速调整
+	BoostWindSpeed = "风速"
+)
+
+// WeightBoost 天气状况权重调整
+type WeightBoost struct {
+	// Source 调整依据，BoostPrecipitation 或 BoostWindSpeed
+	Source string
+	// Threshold 触发调整的阈值
+	Threshold float64
+	// Value 实际值，即最大24小时降水量（毫米）或最大风速（米/秒）
+	Value float64
+	// Factor 权重调整系数
+	Factor float64
+}
+
+// ConditionExplanation 单个天气状况的得分明细
+type ConditionExplanation struct {
+	// Condition 天气状况
+	Condition string
+	// Records 记录条数
+	Records int
+	// Duration 持续时长
+	Duration time.Duration
+	// BaseWeight 天气状况权重
+	BaseWeight float64
+	// Boosts 生效的权重调整
+	Boosts []WeightBoost
+	// AdjustedWeight 调整后的权重
+	AdjustedWeight float64
+	// Score 主导天气策略计算的得分
+	Score float64
+	// Share 得分占全部得分的比例（%）
+	Share float64
+}
+
+// Explanation 主导天气判断过程
+type Explanation struct {
+	// Strategy 主导天气策略名称
+	Strategy string
+	// Conditions 各天气状况得分明细，按得分由高到低排列
+	Conditions []ConditionExplanation
+	// Dominant 主要天气状况
+	Dominant string
+	// RunnerUp 得分第二的天气状况，只有一种天气状况时为空
+	RunnerUp string
+	// Margin 主要天气状况领先第二名的得分
+	Margin float64
+	// Steps 判断过程说明
+	Steps []string
+}
+
+// SetExplain 设置是否在分析结果中给出主导天气判断过程，默认关闭
+func (wa *WeatherAnalyzer) SetExplain(enabled bool) {
+	wa.set(WithExplain(enabled))
+}
+
+// explainDominance 生成主导天气判断过程
+func explainDominance(strategy string, ranked []ConditionStats, scores map[string]float64, boosts map[string][]WeightBoost, dominant string, family WeatherFamily, byFamily bool) *Explanation {
+	e := &Explanation{Strategy: strategy, Dominant: dominant}
+	var total float64
+	for _, s := range ranked {
+		total += scores[s.Condition]
+	}
+	for _, s := range ranked {
+		c := ConditionExplanation{
+			Condition:      s.Condition,
+			Records:        s.Records,
+			Duration:       s.Duration,
+			BaseWeight:     s.BaseWeight,
+			Boosts:         boosts[s.Condition],
+			AdjustedWeight: s.AdjustedWeight,
+			Score:          scores[s.Condition],
+		}
+		if total > 0 {
+			c.Share = c.Score / total * 100
+		}
+		e.Conditions = append(e.Conditions, c)
+	}
+
+	e.Steps = append(e.Steps, fmt.Sprintf("按%s策略计算%d种天气状况的得分", strategy, len(ranked)))
+	for _, c := range e.Conditions {
+		for _, b := range c.Boosts {
+			e.Steps = append(e.Steps, fmt.Sprintf("%s：%s%.1f≥阈值%g，权重×%g", c.Condition, b.Source, b.Value, b.Threshold, b.Factor))
+		}
+	}
+	if byFamily {
+		e.Steps = append(e.Steps, fmt.Sprintf("按天气大类汇总，%s类得分最高，细化为%s", family, dominant))
+	}
+
+	// 第二名为主要天气状况以外得分最高者，按大类汇总时主要天气状况不一定得分最高
+	dominantScore := scores[dominant]
+	for _, c := range e.Conditions {
+		if c.Condition != dominant {
+			e.RunnerUp, e.Margin = c.Condition, dominantScore-c.Score
+			break
+		}
+	}
+	switch {
+	case dominant == "":
+		e.Steps = append(e.Steps, "没有得分大于0的天气状况")
+	case e.RunnerUp == "":
+		e.Steps = append(e.Steps, fmt.Sprintf("主要天气状况为%s，得分%.2f", dominant, dominantScore))
+	default:
+		e.Steps = append(e.Steps, fmt.Sprintf("主要天气状况为%s，得分%.2f，领先%s %.2f", dominant, dominantScore, e.RunnerUp, e.Margin))
+	}
+	return e
+}
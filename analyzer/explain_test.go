@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour, condition := range []string{"多云", "多云", "多云", "中雨", "中雨", "中雨"} {
+		c := WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 22.0,
+			Condition:   condition,
+			Humidity:    85.0,
+			WindSpeed:   3.0,
+		}
+		if condition == "中雨" {
+			c.Precipitation = 4.0
+		}
+		conditions = append(conditions, c)
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.Explanation != nil {
+		t.Error("默认不应给出判断过程")
+	}
+
+	analyzer.SetExplain(true)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	e := result.Explanation
+	if e == nil || e.Dominant != "中雨" || e.RunnerUp != "多云" || e.Strategy != "weighted" {
+		t.Fatalf("判断过程错误: %+v", e)
+	}
+	rain := e.Conditions[0]
+	// 最大24小时降水量12毫米超过中雨阈值10毫米，权重0.88×1.2
+	if rain.Records != 3 || rain.BaseWeight != 0.88 || len(rain.Boosts) != 1 || rain.Boosts[0].Source != BoostPrecipitation {
+		t.Errorf("中雨得分明细错误: %+v", rain)
+	}
+	if math.Abs(rain.Score-0.88*1.2*3) > 1e-9 || math.Abs(e.Margin-(rain.Score-0.55*3)) > 1e-9 {
+		t.Errorf("得分或领先幅度错误，得分 %.3f，领先 %.3f", rain.Score, e.Margin)
+	}
+	if math.Abs(rain.Share+e.Conditions[1].Share-100) > 1e-9 {
+		t.Errorf("得分占比错误: %.2f + %.2f", rain.Share, e.Conditions[1].Share)
+	}
+	if last := e.Steps[len(e.Steps)-1]; !strings.Contains(last, "领先多云") {
+		t.Errorf("判断过程说明错误: %v", e.Steps)
+	}
+}
@@ -0,0 +1,257 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PrecipitationFill 缺测时段降水量填补方式
+type PrecipitationFill int
+
+const (
+	// PrecipitationFillZero 降水量置零(默认)
+	PrecipitationFillZero PrecipitationFill = iota
+	// PrecipitationFillNearest 降水量取时间最近的记录
+	PrecipitationFillNearest
+)
+
+// GapFillOptions 缺测填补选项
+// 温度、湿度、风速按前后记录线性插值，天气状况沿用前一条记录
+type GapFillOptions struct {
+	// Enabled 是否填补缺测记录
+	Enabled bool
+	// Precipitation 降水量填补方式
+	Precipitation PrecipitationFill
+}
+
+// DataGap 缺测时段
+type DataGap struct {
+	// Start 缺测时段前最后一条记录的时间，时段位于期望时间范围开头时为范围起点
+	Start time.Time
+	// End 缺测时段后第一条记录的时间，时段位于期望时间范围末尾时为范围终点
+	End time.Time
+	// Missing 缺测记录条数
+	Missing int
+	// Interval 缺测时段处的期望观测间隔
+	Interval time.Duration
+}
+
+// completeness 数据完整性
+type completeness struct {
+	interval time.Duration
+	percent  float64
+	gaps     []DataGap
+	// missing 各缺测时段中缺测记录的期望时间，与 gaps 一一对应
+	missing [][]time.Time
+}
+
+// detectInterval 取相邻记录时间间隔的中位数作为期望间隔，无法判断时为1小时
+func detectInterval(records []AnalyzedRecord) time.Duration {
+	var intervals []time.Duration
+	for i := 1; i < len(records); i++ {
+		if d := records[i].At.Sub(records[i-1].At); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	if len(intervals) == 0 {
+		return time.Hour
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+// detectGaps 按期望间隔检测缺测时段并计算数据完整性(%)
+// interval 为0时自动判断，并按相邻间隔判断局部观测频率(同 assignDurations)，混合观测频率的数据不会误报缺测；
+// start、end 非零时同时检测期望时间范围首尾的缺测
+func (tl *timeline) detectGaps(interval time.Duration, start, end time.Time) completeness {
+	if !tl.timed || len(tl.records) == 0 {
+		return completeness{percent: 100}
+	}
+	local := interval <= 0
+	if local {
+		interval = detectInterval(tl.records)
+	}
+	c := completeness{interval: interval}
+
+	var times []time.Time
+	for i, r := range tl.records {
+		if i == 0 || !r.At.Equal(tl.records[i-1].At) {
+			times = append(times, r.At)
+		}
+	}
+	intervals := make([]time.Duration, len(times)-1)
+	for k := range intervals {
+		intervals[k] = times[k+1].Sub(times[k])
+	}
+	// cadence 第 k 个间隔处的期望间隔，自动判断时取全局间隔与相邻间隔中的最大值
+	cadence := func(k int) time.Duration {
+		if !local || len(intervals) == 0 {
+			return interval
+		}
+		neighbour := interval
+		if k > 0 {
+			neighbour = max(neighbour, intervals[k-1])
+		}
+		if k+1 < len(intervals) {
+			neighbour = max(neighbour, intervals[k+1])
+		}
+		return neighbour
+	}
+	steps := func(d, step time.Duration) int {
+		return int(math.Round(float64(d) / float64(step)))
+	}
+
+	first, last := times[0], times[len(times)-1]
+	if start.IsZero() || start.After(first) {
+		start = first
+	}
+	if end.IsZero() || end.Before(last) {
+		end = last
+	}
+	expected := 1
+	// addGap 记录缺测时段，from 为第一条缺测记录的期望时间
+	addGap := func(g DataGap, from time.Time) {
+		at := make([]time.Time, g.Missing)
+		for j := range at {
+			at[j] = from.Add(time.Duration(j) * g.Interval)
+		}
+		c.gaps = append(c.gaps, g)
+		c.missing = append(c.missing, at)
+	}
+	if step := cadence(0); steps(first.Sub(start), step) > 0 {
+		n := steps(first.Sub(start), step)
+		addGap(DataGap{Start: start, End: first, Missing: n, Interval: step}, start)
+		expected += n
+	}
+	for k, d := range intervals {
+		step := cadence(k)
+		if float64(d) > 1.5*float64(step) {
+			n := steps(d, step)
+			addGap(DataGap{Start: times[k], End: times[k+1], Missing: n - 1, Interval: step}, times[k].Add(step))
+			expected += n
+		} else {
+			expected++
+		}
+	}
+	if step := cadence(len(intervals) - 1); steps(end.Sub(last), step) > 0 {
+		n := steps(end.Sub(last), step)
+		addGap(DataGap{Start: last, End: end, Missing: n, Interval: step}, last.Add(step))
+		expected += n
+	}
+
+	missing := 0
+	for _, g := range c.gaps {
+		missing += g.Missing
+	}
+	c.percent = math.Max(0, math.Min(100, float64(expected-missing)/float64(expected)*100))
+	return c
+}
+
+// within 将数据完整性限定在 in 为 true 的时刻内，用于分段分析，时段外的缺测不计入
+// records 为计算 c 的全部记录；返回的缺测时段沿用原时段的起止时间，Missing 为时段内的缺测条数
+func (c completeness) within(records []AnalyzedRecord, in func(time.Time) bool) completeness {
+	restricted := completeness{interval: c.interval, percent: 100}
+	present := 0
+	for i, r := range records {
+		if !r.Filled && in(r.At) && (i == 0 || !r.At.Equal(records[i-1].At)) {
+			present++
+		}
+	}
+	missing := 0
+	for i, g := range c.gaps {
+		var at []time.Time
+		for _, t := range c.missing[i] {
+			if in(t) {
+				at = append(at, t)
+			}
+		}
+		if len(at) == 0 {
+			continue
+		}
+		g.Missing = len(at)
+		restricted.gaps = append(restricted.gaps, g)
+		restricted.missing = append(restricted.missing, at)
+		missing += len(at)
+	}
+	if expected := present + missing; expected > 0 {
+		restricted.percent = float64(present) / float64(expected) * 100
+	}
+	return restricted
+}
+
+// fillGaps 按各缺测时段的期望间隔填补缺测记录
+func (tl *timeline) fillGaps(c completeness, opts GapFillOptions) {
+	if !opts.Enabled || len(c.gaps) == 0 {
+		return
+	}
+	filled := make([]AnalyzedRecord, 0, len(tl.records))
+	gapAt := make(map[time.Time]DataGap, len(c.gaps))
+	for _, g := range c.gaps {
+		gapAt[g.Start] = g
+	}
+
+	// 期望时间范围开头的缺测，沿用第一条记录
+	first := tl.records[0]
+	if g := c.gaps[0]; g.End.Equal(first.At) && g.Start.Before(first.At) {
+		for k := 0; k < g.Missing; k++ {
+			filled = append(filled, fillRecord(first, first, g.Start.Add(time.Duration(k)*g.Interval), opts))
+		}
+	}
+	for i, r := range tl.records {
+		filled = append(filled, r)
+		// 重复时间记录只在最后一条之后填补
+		if i+1 < len(tl.records) && tl.records[i+1].At.Equal(r.At) {
+			continue
+		}
+		g, ok := gapAt[r.At]
+		if !ok {
+			continue
+		}
+		next := r
+		if i+1 < len(tl.records) {
+			next = tl.records[i+1]
+		}
+		for k := 1; k <= g.Missing; k++ {
+			filled = append(filled, fillRecord(r, next, r.At.Add(time.Duration(k)*g.Interval), opts))
+		}
+	}
+	tl.records = filled
+}
+
+// fillRecord 在 prev 与 next 之间生成 at 时刻的插补记录
+func fillRecord(prev, next AnalyzedRecord, at time.Time, opts GapFillOptions) AnalyzedRecord {
+	f := 0.0
+	if span := next.At.Sub(prev.At); span > 0 {
+		f = float64(at.Sub(prev.At)) / float64(span)
+	}
+	lerp := func(a, b float64) float64 {
+		return a + (b-a)*f
+	}
+	r := AnalyzedRecord{
+		WeatherCondition: WeatherCondition{
+			Time:        at.Format(time.RFC3339),
+			Temperature: lerp(prev.Temperature, next.Temperature),
+			Condition:   prev.Condition,
+			Humidity:    lerp(prev.Humidity, next.Humidity),
+			WindSpeed:   lerp(prev.WindSpeed, next.WindSpeed),
+		},
+		At:     at,
+		Filled: true,
+	}
+	if prev.DewPoint != nil && next.DewPoint != nil {
+		dew := lerp(*prev.DewPoint, *next.DewPoint)
+		r.DewPoint = &dew
+	}
+	if prev.WindDirection != nil {
+		direction := *prev.WindDirection
+		r.WindDirection = &direction
+	}
+	if opts.Precipitation == PrecipitationFillNearest {
+		r.Precipitation = prev.Precipitation
+		if f > 0.5 {
+			r.Precipitation = next.Precipitation
+		}
+	}
+	return r
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeGaps(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	var conditions []WeatherCondition
+	for hour := 0; hour < 22; hour++ {
+		// 缺测 05:00-07:00，且缺少 22:00、23:00
+		if hour >= 5 && hour <= 7 {
+			continue
+		}
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-01-01 %02d:00", hour),
+			Temperature:   float64(hour),
+			Condition:     "小雨",
+			Humidity:      80.0,
+			WindSpeed:     2.0,
+			Precipitation: 1.0,
+		})
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLocation(loc)
+	analyzer.SetExpectedPeriod(time.Date(2024, 1, 1, 0, 0, 0, 0, loc), time.Date(2024, 1, 1, 23, 0, 0, 0, loc))
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.ExpectedInterval != time.Hour {
+		t.Errorf("期望间隔错误，实际 %v", result.ExpectedInterval)
+	}
+	if len(result.Gaps) != 2 || result.Gaps[0].Missing != 3 || result.Gaps[1].Missing != 2 {
+		t.Errorf("缺测时段错误，实际 %+v", result.Gaps)
+	}
+	if fmt.Sprintf("%.2f", result.Completeness) != "79.17" {
+		t.Errorf("数据完整性错误，期望 79.17，实际 %.2f", result.Completeness)
+	}
+
+	analyzer.SetGapFill(GapFillOptions{Enabled: true})
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.Records) != 24 || result.FilledRecords != 5 {
+		t.Fatalf("填补后记录数量错误，实际 %d，填补 %d", len(result.Records), result.FilledRecords)
+	}
+	filled := result.Records[6]
+	if !filled.Filled || filled.Temperature != 6.0 || filled.Condition != "小雨" || filled.Precipitation != 0 {
+		t.Errorf("插补记录错误，实际 %+v", filled)
+	}
+	if last := result.Records[23]; !last.Filled || last.Temperature != 21.0 {
+		t.Errorf("末尾填补记录错误，实际 %+v", last)
+	}
+}
+
+func TestAnalyzeGapsMixedCadence(t *testing.T) {
+	// 00:00-11:50 每10分钟一条，12:00-23:00 每小时一条，数据完整
+	var conditions []WeatherCondition
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for at.Hour() < 12 {
+		conditions = append(conditions, WeatherCondition{Time: at.Format(time.RFC3339), Temperature: 20.0, Condition: "晴", Humidity: 50.0})
+		at = at.Add(10 * time.Minute)
+	}
+	for at.Day() == 1 {
+		conditions = append(conditions, WeatherCondition{Time: at.Format(time.RFC3339), Temperature: 20.0, Condition: "晴", Humidity: 50.0})
+		at = at.Add(time.Hour)
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.Gaps) != 0 || result.Completeness != 100 {
+		t.Errorf("混合观测频率的完整数据不应有缺测，完整性 %.2f，缺测 %+v", result.Completeness, result.Gaps)
+	}
+	if result.Confidence != 1 {
+		t.Errorf("置信度错误，期望 1，实际 %.2f", result.Confidence)
+	}
+
+	// 每小时一条的部分缺测 15:00-17:00，按局部间隔计为缺测3条
+	var gapped []WeatherCondition
+	for _, c := range conditions {
+		if c.Time < "2024-01-01T15:00:00Z" || c.Time > "2024-01-01T17:00:00Z" {
+			gapped = append(gapped, c)
+		}
+	}
+	analyzer, err = NewWeatherAnalyzer(gapped)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetGapFill(GapFillOptions{Enabled: true})
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.Gaps) != 1 || result.Gaps[0].Missing != 3 || result.Gaps[0].Interval != time.Hour || result.FilledRecords != 3 {
+		t.Errorf("缺测时段错误，实际 %+v，填补 %d", result.Gaps, result.FilledRecords)
+	}
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// InferenceMode 按数值推断天气状况的方式
+type InferenceMode int
+
+const (
+	// InferBlank 仅为天气状况为空或“未知”的记录推断天气状况(默认)
+	InferBlank InferenceMode = iota
+	// InferOff 不推断天气状况
+	InferOff
+	// InferCrossCheck 为空白记录推断天气状况，同时检查已有天气状况与观测数值是否相符，不符时产生数据质量标记
+	InferCrossCheck
+)
+
+const (
+	// snowMaxTemperature 推断为降雪的最高温度（摄氏度）
+	snowMaxTemperature = 0.0
+	// sleetMaxTemperature 推断为雨夹雪的最高温度（摄氏度）
+	sleetMaxTemperature = 2.0
+	// dustMaxHumidity 推断为沙尘天气的最高相对湿度（%）
+	dustMaxHumidity = 40.0
+	// fogMinHumidity 推断为雾的最低相对湿度（%）
+	fogMinHumidity = 95.0
+	// fogMaxWindSpeed 推断为雾的最高风速（米/秒）
+	fogMaxWindSpeed = 2.0
+)
+
+// isUnknownCondition 天气状况是否为空或未知
+func isUnknownCondition(condition string) bool {
+	condition = strings.TrimSpace(condition)
+	return condition == "" || condition == "未知"
+}
+
+// inferCondition 按温度、湿度、风速、降水量推断天气状况
+// 有降水时按温度区分雨、雨夹雪、雪；无降水时干燥大风按风速阈值推断为沙尘，近饱和静风为雾，其余按湿度粗略推断云量
+func inferCondition(c WeatherCondition, windSpeedThresholds map[string]float64) string {
+	if c.Precipitation > 0 {
+		switch {
+		case c.Temperature <= snowMaxTemperature:
+			return "雪"
+		case c.Temperature <= sleetMaxTemperature:
+			return "雨夹雪"
+		}
+		return "雨"
+	}
+
+	if c.Humidity <= dustMaxHumidity {
+		var dust string
+		var dustThreshold float64
+		for condition, threshold := range windSpeedThresholds {
+			if c.WindSpeed >= threshold && (dust == "" || threshold > dustThreshold) {
+				dust, dustThreshold = condition, threshold
+			}
+		}
+		if dust != "" {
+			return dust
+		}
+	}
+
+	switch {
+	case c.Humidity >= fogMinHumidity && c.WindSpeed < fogMaxWindSpeed:
+		return "雾"
+	case c.Humidity >= 85:
+		return "阴"
+	case c.Humidity >= 60:
+		return "多云"
+	}
+	return "晴"
+}
+
+// precipitationPhase 天气状况对应的降水相态：雨、雪、雨夹雪，无降水的天气状况返回空字符串
+func precipitationPhase(condition string) string {
+	rain, snow := strings.Contains(condition, "雨"), strings.Contains(condition, "雪")
+	switch {
+	case rain && snow:
+		return "雨夹雪"
+	case snow:
+		return "雪"
+	case rain:
+		return "雨"
+	}
+	return ""
+}
+
+// inferConditions 按推断方式为记录推断天气状况，交叉检查时返回天气状况与观测数值不符的数据质量标记
+func inferConditions(records []AnalyzedRecord, mode InferenceMode, windSpeedThresholds map[string]float64) []ValidationIssue {
+	if mode == InferOff {
+		return nil
+	}
+	var flags []ValidationIssue
+	for i := range records {
+		r := &records[i]
+		inferred := inferCondition(r.WeatherCondition, windSpeedThresholds)
+		if isUnknownCondition(r.Condition) {
+			r.Condition, r.Inferred = inferred, true
+			continue
+		}
+		if mode != InferCrossCheck {
+			continue
+		}
+		// 晴空类天气伴有降水已由合理性检查标记，此处只检查降水有无及相态
+		reported, measured := precipitationPhase(r.Condition), precipitationPhase(inferred)
+		if reported == measured || clearSkyConditions[r.Condition] {
+			continue
+		}
+		flags = append(flags, ValidationIssue{
+			Index:   r.Index,
+			Field:   "Condition",
+			Value:   r.Condition,
+			Code:    utils.ErrConditionMismatch,
+			Rule:    "天气状况的降水有无及相态应与观测数值相符",
+			Message: fmt.Sprintf("天气状况为%s但按观测数值推断为%s", r.Condition, inferred),
+		})
+	}
+	return flags
+}
+
+// refineInferred 将推断为雨、雪的记录细化为实测降水强度等级，如 雨 细化为 中雨
+func refineInferred(records []AnalyzedRecord, weights map[string]float64) {
+	for i := range records {
+		r := &records[i]
+		if !r.Inferred || r.Intensity == "" || (r.Condition != "雨" && r.Condition != "雪") {
+			continue
+		}
+		if _, ok := weights[r.Intensity]; ok {
+			r.Condition = r.Intensity
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestInferCondition(t *testing.T) {
+	analyzer, err := NewWeatherAnalyzer([]WeatherCondition{{Condition: "晴"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		condition WeatherCondition
+		expected  string
+	}{
+		{WeatherCondition{Temperature: 18, Humidity: 90, WindSpeed: 3, Precipitation: 1.2}, "雨"},
+		{WeatherCondition{Temperature: 1, Humidity: 90, WindSpeed: 3, Precipitation: 0.8}, "雨夹雪"},
+		{WeatherCondition{Temperature: -4, Humidity: 80, WindSpeed: 3, Precipitation: 0.5}, "雪"},
+		{WeatherCondition{Temperature: 15, Humidity: 20, WindSpeed: 18, Precipitation: 0}, "沙尘暴"},
+		{WeatherCondition{Temperature: 15, Humidity: 20, WindSpeed: 11, Precipitation: 0}, "扬沙"},
+		{WeatherCondition{Temperature: 8, Humidity: 98, WindSpeed: 0.5, Precipitation: 0}, "雾"},
+		{WeatherCondition{Temperature: 25, Humidity: 45, WindSpeed: 2, Precipitation: 0}, "晴"},
+	}
+	for _, c := range cases {
+		if actual := inferCondition(c.condition, analyzer.cfg.windSpeedThresholds); actual != c.expected {
+			t.Errorf("推断天气状况错误，期望 %s，实际 %s (%+v)", c.expected, actual, c.condition)
+		}
+	}
+}
+
+func TestInferenceModes(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Temperature: 20, Condition: "", Humidity: 90, WindSpeed: 2, Precipitation: 3.0},
+		{Temperature: 20, Condition: "未知", Humidity: 90, WindSpeed: 2, Precipitation: 3.0},
+		{Temperature: 20, Condition: "小雨", Humidity: 70, WindSpeed: 2, Precipitation: 0},
+		{Temperature: -5, Condition: "中雨", Humidity: 80, WindSpeed: 2, Precipitation: 2.0},
+	}
+	for i := range conditions {
+		conditions[i].Time = fmt.Sprintf("2024-01-01 %02d:00", i)
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	// 推断为雨后按实测强度细化为中雨
+	if fmt.Sprint(result.InferredRecords) != "[1 2]" || result.Records[0].Condition != "中雨" {
+		t.Errorf("推断结果错误，推断记录 %v，天气状况 %s", result.InferredRecords, result.Records[0].Condition)
+	}
+	if conditions[1].Condition != "未知" {
+		t.Error("推断不应修改原始数据")
+	}
+	if countFlags(result.QualityFlags, utils.ErrConditionMismatch) != 0 {
+		t.Error("默认方式不应交叉检查天气状况")
+	}
+
+	analyzer.SetInferenceMode(InferCrossCheck)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if n := countFlags(result.QualityFlags, utils.ErrConditionMismatch); n != 2 {
+		t.Errorf("天气状况不符标记数量错误，期望 2，实际 %d", n)
+	}
+
+	analyzer.SetInferenceMode(InferOff)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.InferredRecords) != 0 || result.Records[0].Condition != "" {
+		t.Error("关闭推断后不应推断天气状况")
+	}
+}
+
+// countFlags 统计指定错误码的数据质量标记数量
+func countFlags(flags []ValidationIssue, code utils.ErrorCode) int {
+	n := 0
+	for _, flag := range flags {
+		if errors.Is(flag.err(), code) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// IntensityGrade 降水强度等级
+type IntensityGrade struct {
+	// Name 等级名称，如 中雨、大雪
+	Name string
+	// Min 等级下限（毫米），时段降水量不小于该值即达到该等级
+	Min float64
+}
+
+// IntensityScale 指定时段长度的降水量等级划分，等级按强度由弱到强排列
+type IntensityScale struct {
+	// Window 统计时段长度，如1小时、12小时、24小时
+	Window time.Duration
+	// Grades 降水强度等级
+	Grades []IntensityGrade
+}
+
+// RainfallScales 降雨量等级划分
+// 12小时、24小时等级依据 GB/T 28592-2012《降水量等级》，1小时等级采用气象业务常用的小时雨强划分
+func RainfallScales() []IntensityScale {
+	return []IntensityScale{
+		{Window: time.Hour, Grades: []IntensityGrade{
+			{"小雨", 0.1}, {"中雨", 2.6}, {"大雨", 8.1}, {"暴雨", 16.0},
+		}},
+		{Window: 12 * time.Hour, Grades: []IntensityGrade{
+			{"小雨", 0.1}, {"中雨", 5.0}, {"大雨", 15.0}, {"暴雨", 30.0}, {"大暴雨", 70.0}, {"特大暴雨", 140.0},
+		}},
+		{Window: 24 * time.Hour, Grades: []IntensityGrade{
+			{"小雨", 0.1}, {"中雨", 10.0}, {"大雨", 25.0}, {"暴雨", 50.0}, {"大暴雨", 100.0}, {"特大暴雨", 250.0},
+		}},
+	}
+}
+
+// SnowfallScales 降雪量(融化后的水当量)等级划分，依据 GB/T 28592-2012《降水量等级》
+func SnowfallScales() []IntensityScale {
+	return []IntensityScale{
+		{Window: 12 * time.Hour, Grades: []IntensityGrade{
+			{"小雪", 0.1}, {"中雪", 1.0}, {"大雪", 3.0}, {"暴雪", 6.0}, {"大暴雪", 10.0}, {"特大暴雪", 15.0},
+		}},
+		{Window: 24 * time.Hour, Grades: []IntensityGrade{
+			{"小雪", 0.1}, {"中雪", 2.5}, {"大雪", 5.0}, {"暴雪", 10.0}, {"大暴雪", 20.0}, {"特大暴雪", 30.0},
+		}},
+	}
+}
+
+// IntensityMismatchPolicy 天气状况与实测降水强度不符时的处理策略
+type IntensityMismatchPolicy int
+
+const (
+	// IntensityMismatchFlag 仅产生数据质量标记(默认)
+	IntensityMismatchFlag IntensityMismatchPolicy = iota
+	// IntensityMismatchUpgrade 实测强度更强时将天气状况改为实测等级，如中雨改为大雨，同时产生数据质量标记
+	IntensityMismatchUpgrade
+)
+
+// intensityRanks 各降水强度等级的强弱次序
+var intensityRanks = map[string]int{
+	"小雨": 1, "中雨": 2, "大雨": 3, "暴雨": 4, "大暴雨": 5, "特大暴雨": 6,
+	"小雪": 1, "中雪": 2, "大雪": 3, "暴雪": 4, "大暴雪": 5, "特大暴雪": 6,
+}
+
+// reportedIntensity 天气状况文本对应的降水强度等级，过渡性天气取较强等级
+var reportedIntensity = map[string]string{
+	"毛毛雨/细雨":   "小雨",
+	"小雨":       "小雨",
+	"小到中雨":     "中雨",
+	"中雨":       "中雨",
+	"中到大雨":     "大雨",
+	"大雨":       "大雨",
+	"大到暴雨":     "暴雨",
+	"暴雨":       "暴雨",
+	"暴雨到大暴雨":   "大暴雨",
+	"大暴雨":      "大暴雨",
+	"大暴雨到特大暴雨": "特大暴雨",
+	"特大暴雨":     "特大暴雨",
+	"极端降雨":     "特大暴雨",
+	"小雪":       "小雪",
+	"小到中雪":     "中雪",
+	"中雪":       "中雪",
+	"中到大雪":     "大雪",
+	"大雪":       "大雪",
+	"大到暴雪":     "暴雪",
+	"暴雪":       "暴雪",
+}
+
+// isSnowfall 是否为降雪类天气，雨夹雪等雨雪混合天气按降雪计
+func isSnowfall(condition string) bool {
+	return strings.Contains(condition, "雪")
+}
+
+// recordTimes 各记录用于时段统计的时间，无观测时间时按记录代表的时长依次累计
+func recordTimes(records []AnalyzedRecord, timed bool) []time.Time {
+	times := make([]time.Time, len(records))
+	var offset time.Duration
+	for i, r := range records {
+		if timed {
+			times[i] = r.At
+			continue
+		}
+		times[i] = time.Time{}.Add(offset)
+		offset += r.Duration
+	}
+	return times
+}
+
+// windowPrecipitation 计算截至第 i 条记录的时段降水量，include 为空时统计全部记录
+// 记录代表的时长超过时段长度时按比例折算
+func windowPrecipitation(records []AnalyzedRecord, times []time.Time, i int, window time.Duration, include func(AnalyzedRecord) bool) float64 {
+	var total float64
+	from := times[i].Add(-window)
+	for j := i; j >= 0 && times[j].After(from); j-- {
+		r := records[j]
+		if include != nil && !include(r) {
+			continue
+		}
+		p := r.Precipitation
+		if r.Duration > window {
+			p *= float64(window) / float64(r.Duration)
+		}
+		total += p
+	}
+	return total
+}
+
+// maxWindowPrecipitation 计算任意连续时段内的最大降水量
+func maxWindowPrecipitation(records []AnalyzedRecord, timed bool, window time.Duration) float64 {
+	times := recordTimes(records, timed)
+	var maxPrecipitation float64
+	for i := range records {
+		maxPrecipitation = math.Max(maxPrecipitation, windowPrecipitation(records, times, i, window, nil))
+	}
+	return maxPrecipitation
+}
+
+// phaseScales 降水记录对应的等级划分，降雪按降雪量等级，其余按降雨量等级
+func phaseScales(snow bool) []IntensityScale {
+	if snow {
+		return SnowfallScales()
+	}
+	return RainfallScales()
+}
+
+// gradeFor 时段降水量在等级划分中达到的最强等级，未达到任何等级时为空
+func gradeFor(grades []IntensityGrade, total float64) string {
+	var name string
+	for _, grade := range grades {
+		if total >= grade.Min {
+			name = grade.Name
+		}
+	}
+	return name
+}
+
+// stronger 两个降水强度等级中较强的一个，相同等级时取 a
+func stronger(a, b string) string {
+	if intensityRanks[b] > intensityRanks[a] {
+		return b
+	}
+	return a
+}
+
+// classifyIntensity 按小时降水量为每条降水记录划分强度等级，记录代表的时长超过1小时时按比例折算
+// 降雪没有小时等级，按小时降雪量对照最短时段的等级划分，即该小时降雪量本身已达到的等级
+// 12小时、24小时累计降水量只用于整体降水强度，见 overallIntensity
+// 天气状况与实测强度不符时产生数据质量标记，按策略升级天气状况并返回被升级的记录序号
+// weights 用于判断升级后的天气状况是否可参与分析，没有对应权重时仅标记
+func classifyIntensity(records []AnalyzedRecord, timed bool, policy IntensityMismatchPolicy, weights map[string]float64) ([]ValidationIssue, []int) {
+	times := recordTimes(records, timed)
+	var flags []ValidationIssue
+	var upgraded []int
+	for i := range records {
+		r := &records[i]
+		r.Intensity = ""
+		if r.Precipitation <= 0 {
+			continue
+		}
+		snow := isSnowfall(r.Condition)
+		samePhase := func(other AnalyzedRecord) bool {
+			return isSnowfall(other.Condition) == snow
+		}
+		hourly := windowPrecipitation(records, times, i, time.Hour, samePhase)
+		r.Intensity = gradeFor(phaseScales(snow)[0].Grades, hourly)
+
+		reported, ok := reportedIntensity[r.Condition]
+		if !ok || r.Intensity == "" || reported == r.Intensity || r.Filled {
+			continue
+		}
+		flags = append(flags, ValidationIssue{
+			Index:   r.Index,
+			Field:   "Condition",
+			Value:   r.Condition,
+			Code:    utils.ErrIntensityMismatch,
+			Rule:    "天气状况应与实测降水强度等级一致",
+			Message: fmt.Sprintf("天气状况为%s但实测降水强度为%s", r.Condition, r.Intensity),
+		})
+		if policy == IntensityMismatchUpgrade && intensityRanks[r.Intensity] > intensityRanks[reported] {
+			if _, ok := weights[r.Intensity]; ok {
+				r.Condition = r.Intensity
+				upgraded = append(upgraded, r.Index)
+			}
+		}
+	}
+	return flags, upgraded
+}
+
+// overallIntensity 整体降水强度等级，取各记录的小时强度及任意连续12小时、24小时累计降水量等级中最强的等级
+// 相同等级时取先出现者
+func overallIntensity(records []AnalyzedRecord, timed bool) string {
+	times := recordTimes(records, timed)
+	var strongest string
+	for i, r := range records {
+		if r.Precipitation <= 0 {
+			continue
+		}
+		strongest = stronger(strongest, r.Intensity)
+		snow := isSnowfall(r.Condition)
+		samePhase := func(other AnalyzedRecord) bool {
+			return isSnowfall(other.Condition) == snow
+		}
+		for _, scale := range phaseScales(snow) {
+			if scale.Window <= time.Hour {
+				continue
+			}
+			total := windowPrecipitation(records, times, i, scale.Window, samePhase)
+			strongest = stronger(strongest, gradeFor(scale.Grades, total))
+		}
+	}
+	return strongest
+}
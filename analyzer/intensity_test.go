@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestPrecipitationIntensity(t *testing.T) {
+	precipitation := []float64{0.5, 3.0, 12.0, 20.0, 1.0, 0}
+	var conditions []WeatherCondition
+	for hour, p := range precipitation {
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature:   24.0,
+			Condition:     "中雨",
+			Humidity:      90.0,
+			WindSpeed:     3.0,
+			Precipitation: p,
+		})
+	}
+	conditions[5].Condition = "阴"
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	// 每条记录按小时雨强划分，第5小时雨强较弱仍为小雨
+	expected := []string{"小雨", "中雨", "大雨", "暴雨", "小雨", ""}
+	for i, r := range result.Records {
+		if r.Intensity != expected[i] {
+			t.Errorf("第%d条记录降水强度错误，期望 %q，实际 %q", i+1, expected[i], r.Intensity)
+		}
+	}
+	if result.PrecipitationIntensity != "暴雨" {
+		t.Errorf("最强降水强度错误，期望 暴雨，实际 %s", result.PrecipitationIntensity)
+	}
+	if result.MaxPrecipitation24h != 36.5 {
+		t.Errorf("最大24小时降水量错误，期望 36.5，实际 %.1f", result.MaxPrecipitation24h)
+	}
+
+	if mismatches := countFlags(result.QualityFlags, utils.ErrIntensityMismatch); mismatches != 4 {
+		t.Errorf("降水强度不符标记数量错误，期望 4，实际 %d", mismatches)
+	}
+	if result.Records[3].Condition != "中雨" || len(result.UpgradedRecords) != 0 {
+		t.Error("默认策略不应修改天气状况")
+	}
+
+	analyzer.SetIntensityMismatchPolicy(IntensityMismatchUpgrade)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	// 仅升级实测强度更强的记录，第1条小雨不降级
+	if fmt.Sprint(result.UpgradedRecords) != "[3 4]" {
+		t.Errorf("升级记录错误，实际 %v", result.UpgradedRecords)
+	}
+	if result.ConditionDurations["暴雨"] != time.Hour || result.ConditionDurations["大雨"] != time.Hour {
+		t.Errorf("升级后天气状况时长错误，实际 %v", result.ConditionDurations)
+	}
+	if conditions[3].Condition != "中雨" {
+		t.Error("升级不应修改原始数据")
+	}
+}
+
+func TestSteadyLightRainIntensity(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 24; hour++ {
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature:   22.0,
+			Condition:     "小雨",
+			Humidity:      90.0,
+			WindSpeed:     2.0,
+			Precipitation: 0.5,
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetIntensityMismatchPolicy(IntensityMismatchUpgrade)
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	// 持续0.5毫米/小时的小雨，每条记录均为小雨，24小时累计12毫米只体现在整体降水强度中
+	for i, r := range result.Records {
+		if r.Intensity != "小雨" {
+			t.Errorf("第%d条记录降水强度错误，期望 小雨，实际 %q", i+1, r.Intensity)
+		}
+	}
+	if mismatches := countFlags(result.QualityFlags, utils.ErrIntensityMismatch); mismatches != 0 {
+		t.Errorf("持续小雨不应产生降水强度不符标记，实际 %d", mismatches)
+	}
+	if len(result.UpgradedRecords) != 0 || result.DominantCondition != "小雨" || result.TransitionText != "" {
+		t.Errorf("持续小雨不应升级天气状况，实际升级 %v，主导天气 %s，转变描述 %s",
+			result.UpgradedRecords, result.DominantCondition, result.TransitionText)
+	}
+	if result.PrecipitationIntensity != "中雨" || result.MaxPrecipitation24h != 12 {
+		t.Errorf("整体降水强度错误，期望 中雨/12，实际 %s/%.1f", result.PrecipitationIntensity, result.MaxPrecipitation24h)
+	}
+}
+
+func TestSnowfallIntensity(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Temperature: -3.0, Condition: "小雪", Humidity: 85.0, Precipitation: 1.5},
+		{Temperature: -3.0, Condition: "小雪", Humidity: 85.0, Precipitation: 2.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	// 每条记录按本身的小时降雪量划分，无观测时间时按每条1小时累计，12小时降雪量3.5毫米为大雪
+	if result.Records[0].Intensity != "中雪" || result.Records[1].Intensity != "中雪" {
+		t.Errorf("降雪强度错误，实际 %s、%s", result.Records[0].Intensity, result.Records[1].Intensity)
+	}
+	if result.PrecipitationIntensity != "大雪" {
+		t.Errorf("最强降雪强度错误，期望 大雪，实际 %s", result.PrecipitationIntensity)
+	}
+}
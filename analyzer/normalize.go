@@ -0,0 +1,244 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/louismax/weather_analyzer/qweather"
+)
+
+// defaultConditionAliases 常见天气状况别名
+var defaultConditionAliases = map[string]string{
+	"毛毛雨":    "毛毛雨/细雨",
+	"细雨":     "毛毛雨/细雨",
+	"晴天":     "晴",
+	"晴朗":     "晴",
+	"阴天":     "阴",
+	"雷雨":     "雷阵雨",
+	"雷阵雨伴冰雹": "雷阵雨伴有冰雹",
+	"冰雹":     "雷阵雨伴有冰雹",
+	"雨加雪":    "雨夹雪",
+	"沙尘":     "沙尘暴",
+	"暴风雪":    "暴雪",
+	"雾霾":     "霾",
+	"高温":     "热",
+	"低温":     "冷",
+}
+
+// SetConditionAliases 设置天气状况别名，如 {"大太阳": "晴"}，别名对应的天气状况需存在权重
+// 别名及对应的天气状况不能为空，否则返回 ErrInvalidConfig 错误且不做任何修改
+func (wa *WeatherAnalyzer) SetConditionAliases(aliases map[string]string) error {
+	before := pickMap(wa.cfg.conditionAliases, aliases)
+	if err := wa.apply(WithConditionAliases(aliases)); err != nil {
+		return err
+	}
+	logOverrides(wa.cfg.log(), "天气状况别名", before, aliases)
+	return nil
+}
+
+// NormalizeCondition 将天气状况文本规范化为权重表中的天气状况
+// 支持去除空白、别名、和风天气图标代码及英文天气状况、“小雨转中雨”“小雨到中雨”等组合及模糊匹配，无法识别时返回去除空白后的原文及 false
+func (a *Analyzer) NormalizeCondition(text string) (string, bool) {
+	text = strings.Join(strings.Fields(text), " ")
+	if isUnknownCondition(text) {
+		return text, true
+	}
+	if condition, ok := a.lookupCondition(text); ok {
+		return condition, true
+	}
+	if condition, ok := a.combinedCondition(text); ok {
+		return condition, true
+	}
+	if condition, ok := a.fuzzyCondition(text); ok {
+		return condition, true
+	}
+	return text, false
+}
+
+// normalizedCondition 规范化后的天气状况，无法识别时为去除空白后的原文
+func (a *Analyzer) normalizedCondition(text string) string {
+	condition, _ := a.NormalizeCondition(text)
+	return condition
+}
+
+// NormalizeCondition 将天气状况文本规范化为权重表中的天气状况，规则同 Analyzer.NormalizeCondition
+func (wa *WeatherAnalyzer) NormalizeCondition(text string) (string, bool) {
+	return wa.analyzer().NormalizeCondition(text)
+}
+
+// lookupCondition 精确查找天气状况
+func (a *Analyzer) lookupCondition(text string) (string, bool) {
+	compact := strings.ReplaceAll(text, " ", "")
+	if _, ok := a.cfg.conditionWeights[compact]; ok {
+		return compact, true
+	}
+	if condition, ok := a.cfg.conditionAliases[compact]; ok {
+		return condition, true
+	}
+	if condition, ok := qweather.WeatherTextByIconCode(compact); ok {
+		return condition, true
+	}
+	if condition, ok := qweather.WeatherTextByEnglish(text); ok {
+		return condition, true
+	}
+	return "", false
+}
+
+// combinedCondition 识别“A转B”“A到B”形式的组合天气
+// “小雨到中雨”等可合并为“小到中雨”的取合并后的天气状况，其余取权重最高的天气状况
+func (a *Analyzer) combinedCondition(text string) (string, bool) {
+	for _, sep := range []string{"转", "到", "/", "~"} {
+		parts := strings.Split(strings.ReplaceAll(text, " ", ""), sep)
+		if len(parts) < 2 {
+			continue
+		}
+		var conditions []string
+		for _, part := range parts {
+			condition, ok := a.lookupCondition(part)
+			if !ok {
+				return "", false
+			}
+			conditions = append(conditions, condition)
+		}
+		if sep == "到" && len(conditions) == 2 {
+			// 小雨到中雨 -> 小到中雨
+			first := []rune(conditions[0])
+			merged := string(first[:len(first)-1]) + "到" + conditions[1]
+			if _, ok := a.cfg.conditionWeights[merged]; ok && len(first) > 1 {
+				return merged, true
+			}
+		}
+		best := conditions[0]
+		for _, condition := range conditions[1:] {
+			if a.cfg.conditionWeights[condition] > a.cfg.conditionWeights[best] {
+				best = condition
+			}
+		}
+		return best, true
+	}
+	return "", false
+}
+
+// fuzzyMinLength 英文按编辑距离模糊匹配的最短文本长度，过短的文本(如 Ho)容易误配
+const fuzzyMinLength = 5
+
+// negationWords 否定词，包含否定词的文本(如“无雨”“不冷”)不做模糊匹配
+const negationWords = "无不没"
+
+// fuzzyCondition 模糊匹配天气状况
+// 中文取文本中包含的最长天气状况或别名(不含单字，如“雪后初晴”不视为雪)，如“午后雷阵雨”为雷阵雨，包含否定词时不匹配；
+// 英文取编辑距离不超过2且唯一最接近的和风天气英文天气状况，文本不少于5个字符
+func (a *Analyzer) fuzzyCondition(text string) (string, bool) {
+	if isASCII(text) {
+		if len(strings.ReplaceAll(text, " ", "")) < fuzzyMinLength {
+			return "", false
+		}
+		lower := strings.ToLower(text)
+		best, bestDistance, unique := "", 3, false
+		for _, candidate := range qweather.EnglishWeatherTexts() {
+			d := levenshtein(lower, candidate)
+			switch {
+			case d < bestDistance:
+				best, bestDistance, unique = candidate, d, true
+			case d == bestDistance:
+				unique = false
+			}
+		}
+		if !unique {
+			return "", false
+		}
+		return qweather.WeatherTextByEnglish(best)
+	}
+
+	compact := strings.ReplaceAll(text, " ", "")
+	if strings.ContainsAny(compact, negationWords) {
+		return "", false
+	}
+	candidates := make(map[string]string)
+	for condition := range a.cfg.conditionWeights {
+		candidates[condition] = condition
+	}
+	for alias, condition := range a.cfg.conditionAliases {
+		candidates[alias] = condition
+	}
+	var matches []string
+	for candidate := range candidates {
+		if utf8.RuneCountInString(candidate) > 1 && strings.Contains(compact, candidate) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+	// 最长者优先，长度相同取权重较高者
+	sort.Slice(matches, func(i, j int) bool {
+		li, lj := len([]rune(matches[i])), len([]rune(matches[j]))
+		if li != lj {
+			return li > lj
+		}
+		wi, wj := a.cfg.conditionWeights[candidates[matches[i]]], a.cfg.conditionWeights[candidates[matches[j]]]
+		if wi != wj {
+			return wi > wj
+		}
+		return matches[i] < matches[j]
+	})
+	return candidates[matches[0]], true
+}
+
+// normalizeConditions 规范化全部记录的天气状况，原始文本保存在 RawCondition
+func (a *Analyzer) normalizeConditions(records []AnalyzedRecord) {
+	for i := range records {
+		r := &records[i]
+		r.RawCondition = r.Condition
+		r.Condition = a.normalizedCondition(r.Condition)
+	}
+}
+
+// unknownConditions 无法识别(没有权重)的天气状况，按名称排序
+func (a *Analyzer) unknownConditions(records []AnalyzedRecord) []string {
+	seen := make(map[string]bool)
+	var unknown []string
+	for _, r := range records {
+		if _, ok := a.cfg.conditionWeights[r.Condition]; ok || isUnknownCondition(r.Condition) || seen[r.Condition] {
+			continue
+		}
+		seen[r.Condition] = true
+		unknown = append(unknown, r.Condition)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// isASCII 文本是否仅包含ASCII字符
+func isASCII(text string) bool {
+	for _, r := range text {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(rb)]
+}
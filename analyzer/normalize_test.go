@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalizeCondition(t *testing.T) {
+	analyzer, err := NewWeatherAnalyzer([]WeatherCondition{{Condition: "晴"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"阵雨 ":         "阵雨",
+		"　多云":         "多云",
+		"毛毛雨":         "毛毛雨/细雨",
+		"Light Rain":  "小雨",
+		"heavy  snow": "大雪",
+		"Ligth Rain":  "小雨",
+		"305":         "小雨",
+		"154":         "154",
+		"小雨转中雨":       "中雨",
+		"晴转多云":        "多云",
+		"小雨到中雨":       "小到中雨",
+		"午后雷阵雨":       "雷阵雨",
+		"未知":          "未知",
+	}
+	for text, expected := range cases {
+		actual, _ := analyzer.NormalizeCondition(text)
+		if actual != expected {
+			t.Errorf("规范化 %q 错误，期望 %s，实际 %s", text, expected, actual)
+		}
+	}
+	if _, ok := analyzer.NormalizeCondition("龙卷风"); ok {
+		t.Error("期望无法识别龙卷风")
+	}
+	// 否定、单字及过短的英文不做模糊匹配，视为无法识别
+	for _, text := range []string{"无雨", "不冷", "没有下雪", "雪后初晴", "Ho", "Sun"} {
+		if condition, ok := analyzer.NormalizeCondition(text); ok {
+			t.Errorf("期望无法识别 %q，实际为 %s", text, condition)
+		}
+	}
+
+	analyzer.SetConditionAliases(map[string]string{"大太阳": "晴"})
+	if condition, ok := analyzer.NormalizeCondition("大太阳"); !ok || condition != "晴" {
+		t.Errorf("自定义别名错误，实际 %s", condition)
+	}
+}
+
+func TestUnknownConditions(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Temperature: 20, Condition: "Light Rain", Humidity: 80, Precipitation: 0.5},
+		{Temperature: 20, Condition: "小雨 ", Humidity: 80, Precipitation: 0.5},
+		{Temperature: 20, Condition: "龙卷风", Humidity: 80},
+	}
+	for i := range conditions {
+		conditions[i].Time = fmt.Sprintf("2024-07-01 %02d:00", i)
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.ConditionDurations["小雨"].Hours() != 2 {
+		t.Errorf("规范化后小雨时长错误，实际 %v", result.ConditionDurations)
+	}
+	if result.Records[0].RawCondition != "Light Rain" {
+		t.Errorf("原始天气状况错误，实际 %s", result.Records[0].RawCondition)
+	}
+	if fmt.Sprint(result.UnknownConditions) != "[龙卷风]" {
+		t.Errorf("无法识别的天气状况错误，实际 %v", result.UnknownConditions)
+	}
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/louismax/weather_analyzer/qweather"
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// 内置区域气候配置名称
+const (
+	// ProfileSouthChina 华南湿润区
+	ProfileSouthChina = "south-china"
+	// ProfileNorthChina 华北及东北
+	ProfileNorthChina = "north-china"
+	// ProfilePlateau 青藏高原
+	ProfilePlateau = "plateau"
+	// ProfileNorthwestArid 西北干旱区
+	ProfileNorthwestArid = "northwest-arid"
+	// ProfileCoastal 东部沿海
+	ProfileCoastal = "coastal"
+)
+
+// profileFS 内置区域气候配置，每个文件为一份在默认配置基础上调整的配置文件
+//
+//go:embed profiles/*.json
+var profileFS embed.FS
+
+var (
+	profilesOnce sync.Once
+	profiles     map[string]*ConfigFile
+)
+
+// loadProfiles 解析内置区域气候配置，内置配置无效属于编程错误
+func loadProfiles() map[string]*ConfigFile {
+	profilesOnce.Do(func() {
+		entries, err := profileFS.ReadDir("profiles")
+		if err != nil {
+			panic(fmt.Sprintf("读取内置区域气候配置失败: %v", err))
+		}
+		profiles = make(map[string]*ConfigFile, len(entries))
+		for _, entry := range entries {
+			data, err := profileFS.ReadFile(path.Join("profiles", entry.Name()))
+			if err != nil {
+				panic(fmt.Sprintf("读取内置区域气候配置失败: %v", err))
+			}
+			f, err := ParseConfig(data, ConfigJSON)
+			if err != nil || f.Profile != "" {
+				panic(fmt.Sprintf("内置区域气候配置 %s 无效: %v", entry.Name(), err))
+			}
+			profiles[strings.TrimSuffix(entry.Name(), ".json")] = f
+		}
+	})
+	return profiles
+}
+
+// Profiles 内置区域气候配置名称，按名称排序
+func Profiles() []string {
+	return sortedKeys(loadProfiles())
+}
+
+// LookupProfile 按名称查找内置区域气候配置，返回配置副本
+func LookupProfile(name string) (*ConfigFile, bool) {
+	f, ok := loadProfiles()[name]
+	if !ok {
+		return nil, false
+	}
+	return f.clone(), true
+}
+
+// unknownProfileError 未知区域气候配置错误
+func unknownProfileError(name string) error {
+	return &utils.WeatherError{
+		Code:    utils.ErrInvalidConfig,
+		Message: fmt.Sprintf("未知的区域气候配置: %s，可选 %s", name, strings.Join(Profiles(), "、")),
+		Field:   "profile",
+		Value:   name,
+	}
+}
+
+// WithProfile 在当前配置基础上应用区域气候配置，其后的选项可继续覆盖其中的项
+func WithProfile(name string) Option {
+	return func(c *AnalyzerConfig) error {
+		f, ok := loadProfiles()[name]
+		if !ok {
+			return unknownProfileError(name)
+		}
+		if err := c.applyCheckedConfigFile(f); err != nil {
+			return err
+		}
+		c.profile = name
+		return nil
+	}
+}
+
+// SetProfile 在当前配置基础上应用区域气候配置，如 south-china、north-china
+func (wa *WeatherAnalyzer) SetProfile(name string) error {
+	return wa.apply(WithProfile(name))
+}
+
+// Profile 已应用的区域气候配置名称，未应用时为空
+func (c *AnalyzerConfig) Profile() string {
+	return c.profile
+}
+
+// 按省级行政区划分的区域气候配置
+var provinceProfiles = map[string]string{
+	"广东": ProfileSouthChina, "广西": ProfileSouthChina, "海南": ProfileSouthChina, "福建": ProfileSouthChina,
+	"香港": ProfileSouthChina, "澳门": ProfileSouthChina, "台湾": ProfileSouthChina,
+	"北京": ProfileNorthChina, "天津": ProfileNorthChina, "河北": ProfileNorthChina, "山西": ProfileNorthChina,
+	"山东": ProfileNorthChina, "河南": ProfileNorthChina, "陕西": ProfileNorthChina,
+	"辽宁": ProfileNorthChina, "吉林": ProfileNorthChina, "黑龙江": ProfileNorthChina,
+	"西藏": ProfilePlateau, "青海": ProfilePlateau,
+	"新疆": ProfileNorthwestArid, "甘肃": ProfileNorthwestArid, "宁夏": ProfileNorthwestArid,
+	"上海": ProfileCoastal,
+}
+
+// coastalCities 东部沿海城市(地级)
+var coastalCities = map[string]bool{
+	"大连": true, "丹东": true, "营口": true, "锦州": true, "葫芦岛": true, "盘锦": true,
+	"秦皇岛": true, "唐山": true, "沧州": true, "天津": true,
+	"青岛": true, "烟台": true, "威海": true, "日照": true, "东营": true, "潍坊": true, "滨州": true,
+	"连云港": true, "盐城": true, "南通": true, "上海": true,
+	"舟山": true, "宁波": true, "台州": true, "温州": true,
+}
+
+// ProfileForCity 按和风天气城市信息(省级行政区、所属城市及经纬度)推断区域气候配置，无法推断时返回 false
+// 东部沿海城市优先使用 coastal，四川西部(东经102.5°以西)视为高原，内蒙古以东经110°为界分属西北干旱区与华北，
+// 缺少省级行政区时按经纬度粗略划分
+func ProfileForCity(city qweather.ResultGeoCityLookupInfo) (string, bool) {
+	if city.Country != "" && !strings.HasPrefix(city.Country, "中国") {
+		return "", false
+	}
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(city.Lat), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(city.Lon), 64)
+	adm1 := trimAdminSuffix(city.Adm1)
+
+	if coastalCities[trimAdminSuffix(city.Adm2)] || coastalCities[trimAdminSuffix(city.Name)] {
+		return ProfileCoastal, true
+	}
+	switch adm1 {
+	case "四川":
+		if lonErr == nil && lon < 102.5 {
+			return ProfilePlateau, true
+		}
+		return "", false
+	case "内蒙古":
+		if lonErr == nil && lon < 110 {
+			return ProfileNorthwestArid, true
+		}
+		return ProfileNorthChina, true
+	}
+	if profile, ok := provinceProfiles[adm1]; ok || adm1 != "" || latErr != nil || lonErr != nil {
+		return profile, ok
+	}
+	return profileForCoordinates(lat, lon)
+}
+
+// profileForCoordinates 按经纬度粗略划分区域气候配置
+func profileForCoordinates(lat, lon float64) (string, bool) {
+	switch {
+	case lon < 104 && lat > 36:
+		return ProfileNorthwestArid, true
+	case lon < 103 && lat >= 27:
+		return ProfilePlateau, true
+	case lon > 105 && lat < 25.5:
+		return ProfileSouthChina, true
+	case lon >= 104 && lat > 34:
+		return ProfileNorthChina, true
+	}
+	return "", false
+}
+
+// trimAdminSuffix 去除行政区划名称中的省、市、自治区等后缀，如 广西壮族自治区 → 广西
+func trimAdminSuffix(name string) string {
+	name = strings.TrimSpace(name)
+	for _, suffix := range []string{"维吾尔自治区", "壮族自治区", "回族自治区", "特别行政区", "自治区", "省", "市"} {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != name && trimmed != "" {
+			return trimmed
+		}
+	}
+	return name
+}
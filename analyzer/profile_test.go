@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/louismax/weather_analyzer/qweather"
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestProfiles(t *testing.T) {
+	expected := []string{"coastal", "north-china", "northwest-arid", "plateau", "south-china"}
+	names := Profiles()
+	if len(names) != len(expected) {
+		t.Fatalf("区域气候配置数量错误，期望 %d，实际 %d", len(expected), len(names))
+	}
+	for i, name := range names {
+		if name != expected[i] {
+			t.Errorf("区域气候配置名称错误，期望 %s，实际 %s", expected[i], name)
+		}
+		if f, _ := LookupProfile(name); f.Description == "" {
+			t.Errorf("区域气候配置 %s 缺少说明", name)
+		}
+	}
+
+	cfg, err := NewAnalyzerConfig(
+		WithProfile(ProfileNorthwestArid),
+		WithPrecipitationThresholds(map[string]float64{"暴雨": 30}),
+	)
+	if err != nil {
+		t.Fatalf("创建分析器配置失败: %v", err)
+	}
+	thresholds := cfg.PrecipitationThresholds()
+	if thresholds["中雨"] != 6 || thresholds["暴雨"] != 30 || thresholds["小雨"] != 0.1 {
+		t.Errorf("区域气候配置与自定义配置合并错误: %v", thresholds)
+	}
+	if cfg.Profile() != ProfileNorthwestArid {
+		t.Errorf("区域气候配置名称错误，实际 %s", cfg.Profile())
+	}
+
+	f, err := ParseConfig([]byte("profile: south-china\ncondition_weights:\n  小雨: 0.7\n"), ConfigYAML)
+	if err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+	cfg, err = NewAnalyzerConfig(WithConfigFile(f))
+	if err != nil {
+		t.Fatalf("应用配置失败: %v", err)
+	}
+	if w := cfg.ConditionWeights(); w["小雨"] != 0.7 || w["扬沙"] != 0.55 || cfg.Profile() != ProfileSouthChina {
+		t.Errorf("配置文件应在区域气候配置基础上覆盖: 小雨 %.2f 扬沙 %.2f", w["小雨"], w["扬沙"])
+	}
+
+	if _, err := NewAnalyzerConfig(WithProfile("tropical")); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("未知的区域气候配置应返回 ErrInvalidConfig，实际 %v", err)
+	}
+	if _, err := ParseConfig([]byte(`{"profile": "tropical"}`), ConfigJSON); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("配置文件引用未知的区域气候配置应返回 ErrInvalidConfig，实际 %v", err)
+	}
+}
+
+func TestProfileForCity(t *testing.T) {
+	cases := []struct {
+		city     qweather.ResultGeoCityLookupInfo
+		expected string
+	}{
+		{qweather.ResultGeoCityLookupInfo{Name: "广州", Adm2: "广州", Adm1: "广东省", Country: "中国", Lat: "23.12", Lon: "113.28"}, ProfileSouthChina},
+		{qweather.ResultGeoCityLookupInfo{Name: "乌鲁木齐", Adm2: "乌鲁木齐", Adm1: "新疆维吾尔自治区", Country: "中国", Lat: "43.82", Lon: "87.61"}, ProfileNorthwestArid},
+		{qweather.ResultGeoCityLookupInfo{Name: "拉萨", Adm2: "拉萨", Adm1: "西藏自治区", Country: "中国", Lat: "29.65", Lon: "91.13"}, ProfilePlateau},
+		{qweather.ResultGeoCityLookupInfo{Name: "康定", Adm2: "甘孜", Adm1: "四川省", Country: "中国", Lat: "30.05", Lon: "101.96"}, ProfilePlateau},
+		{qweather.ResultGeoCityLookupInfo{Name: "崂山", Adm2: "青岛", Adm1: "山东省", Country: "中国", Lat: "36.10", Lon: "120.47"}, ProfileCoastal},
+		{qweather.ResultGeoCityLookupInfo{Name: "石家庄", Adm2: "石家庄", Adm1: "河北省", Country: "中国", Lat: "38.04", Lon: "114.51"}, ProfileNorthChina},
+		{qweather.ResultGeoCityLookupInfo{Name: "鄂尔多斯", Adm2: "鄂尔多斯", Adm1: "内蒙古自治区", Country: "中国", Lat: "39.61", Lon: "109.78"}, ProfileNorthwestArid},
+		{qweather.ResultGeoCityLookupInfo{Name: "未知", Lat: "22.8", Lon: "108.3"}, ProfileSouthChina},
+	}
+	for _, c := range cases {
+		if profile, ok := ProfileForCity(c.city); !ok || profile != c.expected {
+			t.Errorf("%s 的区域气候配置错误，期望 %s，实际 %s", c.city.Name, c.expected, profile)
+		}
+	}
+
+	for _, city := range []qweather.ResultGeoCityLookupInfo{
+		{Name: "成都", Adm2: "成都", Adm1: "四川省", Country: "中国", Lat: "30.66", Lon: "104.07"},
+		{Name: "Tokyo", Adm1: "Tokyo", Country: "日本", Lat: "35.68", Lon: "139.76"},
+	} {
+		if profile, ok := ProfileForCity(city); ok {
+			t.Errorf("%s 不应匹配区域气候配置，实际 %s", city.Name, profile)
+		}
+	}
+}
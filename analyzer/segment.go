@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// TimeSegment 分段分析的时段
+type TimeSegment struct {
+	// Name 时段名称，如 白天、夜间、上午
+	Name string
+	// Start 时段开始，为距当日零点的偏移
+	Start time.Duration
+	// End 时段结束(不含)，为距当日零点的偏移；小于等于 Start 时表示跨越午夜
+	End time.Duration
+}
+
+// contains 判断一天中的某一时刻是否位于时段内
+func (s TimeSegment) contains(offset time.Duration) bool {
+	if s.Start < s.End {
+		return offset >= s.Start && offset < s.End
+	}
+	return offset >= s.Start || offset < s.End
+}
+
+// FixedPeriodSegments 固定时段：凌晨(00-06时)、上午(06-12时)、下午(12-18时)、夜间(18-24时)
+func FixedPeriodSegments() []TimeSegment {
+	return []TimeSegment{
+		{Name: "凌晨", Start: 0, End: 6 * time.Hour},
+		{Name: "上午", Start: 6 * time.Hour, End: 12 * time.Hour},
+		{Name: "下午", Start: 12 * time.Hour, End: 18 * time.Hour},
+		{Name: "夜间", Start: 18 * time.Hour, End: 24 * time.Hour},
+	}
+}
+
+// DayNightSegments 按日出日落时间划分白天、夜间，sunrise、sunset 格式为 15:04(如和风天气的 06:45)
+func DayNightSegments(sunrise, sunset string) ([]TimeSegment, error) {
+	rise, err := parseClock(sunrise)
+	if err != nil {
+		return nil, err
+	}
+	set, err := parseClock(sunset)
+	if err != nil {
+		return nil, err
+	}
+	return []TimeSegment{
+		{Name: "白天", Start: rise, End: set},
+		{Name: "夜间", Start: set, End: rise},
+	}, nil
+}
+
+// parseClock 解析 15:04 格式的时刻为距零点的偏移
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, &utils.WeatherError{
+			Code:    utils.ErrInvalidTime,
+			Message: fmt.Sprintf("无法解析时刻: %s", value),
+			Value:   value,
+			Err:     err,
+		}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// SegmentResult 单个时段的分析结果
+type SegmentResult struct {
+	// Name 时段名称
+	Name string
+	// Result 时段内记录的分析结果
+	Result *WeatherAnalysisResult
+}
+
+// SegmentedAnalysisResult 分段分析结果
+type SegmentedAnalysisResult struct {
+	// Segments 各时段分析结果，按时段顺序排列，没有记录的时段不出现
+	Segments []SegmentResult
+	// Description 综合描述，如“白天多云，夜间小雨。”，没有主要天气状况的时段不写入，各时段均没有时为空
+	Description string
+}
+
+// AnalyzeSegments 按时段分段分析天气状况，规则同 Analyzer.AnalyzeSegments
+func (wa *WeatherAnalyzer) AnalyzeSegments(segments []TimeSegment) (*SegmentedAnalysisResult, error) {
+	return wa.analyzer().AnalyzeSegments(context.Background(), wa.conditions, segments)
+}
+
+// AnalyzeSegments 按时段分段分析天气状况，要求全部记录均有有效观测时间
+// 记录按 SetLocation 设置的时区判断所属时段，同名时段跨越多日时合并分析
+func (a *Analyzer) AnalyzeSegments(ctx context.Context, conditions []WeatherCondition, segments []TimeSegment) (*SegmentedAnalysisResult, error) {
+	if len(segments) == 0 {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidInput,
+			Message: "时段不能为空",
+		}
+	}
+	data, err := a.prepare(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+	if !data.timed {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrInvalidTime,
+			Message: "分段分析需要全部记录均有有效的观测时间",
+		}
+	}
+
+	result := &SegmentedAnalysisResult{}
+	var phrases []string
+	for _, segment := range segments {
+		inSegment := func(at time.Time) bool {
+			local := at.In(a.cfg.location)
+			return segment.contains(local.Sub(time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, a.cfg.location)))
+		}
+		var records []AnalyzedRecord
+		for _, r := range data.records {
+			if inSegment(r.At) {
+				records = append(records, r)
+			}
+		}
+		if len(records) == 0 {
+			continue
+		}
+		// 时段内的记录并不连续(如夜间跨越白天)，数据完整性只计入时段内的缺测
+		segmentData := &analysisData{
+			timeline:     &timeline{records: records, timed: true},
+			completeness: data.completeness.within(data.records, inSegment),
+		}
+		segmentResult := a.analyze(segmentData, segment.Name)
+		result.Segments = append(result.Segments, SegmentResult{Name: segment.Name, Result: segmentResult})
+		// 没有主要天气状况(如天气状况均无法识别)的时段不写入综合描述
+		phrase := segmentResult.DominantCondition
+		if segmentResult.TransitionText != "" {
+			phrase = segmentResult.TransitionText
+		}
+		if phrase != "" {
+			phrases = append(phrases, segment.Name+phrase)
+		}
+	}
+	if len(result.Segments) == 0 {
+		return nil, &utils.WeatherError{
+			Code:    utils.ErrEmptyData,
+			Message: "各时段均没有天气数据",
+		}
+	}
+	if len(phrases) > 0 {
+		result.Description = strings.Join(phrases, "，") + "。"
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestAnalyzeSegments(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 24; hour++ {
+		c := WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00+08:00", hour),
+			Temperature: 28.0,
+			Condition:   "多云",
+			Humidity:    70.0,
+			WindSpeed:   2.0,
+		}
+		if hour < 6 || hour >= 19 {
+			c.Condition = "小雨"
+			c.Precipitation = 0.5
+		}
+		conditions = append(conditions, c)
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLocation(time.FixedZone("CST", 8*3600))
+
+	segments, err := DayNightSegments("05:45", "19:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := analyzer.AnalyzeSegments(segments)
+	if err != nil {
+		t.Fatalf("分段分析失败: %v", err)
+	}
+	if len(result.Segments) != 2 {
+		t.Fatalf("时段数量错误，期望 2，实际 %d", len(result.Segments))
+	}
+	if result.Description != "白天多云，夜间小雨。" {
+		t.Errorf("综合描述错误，实际 %s", result.Description)
+	}
+	night := result.Segments[1].Result
+	if len(night.Records) != 10 || !strings.HasPrefix(night.Description, "夜间天气以小雨为主") {
+		t.Errorf("夜间分析结果错误，记录 %d 条，描述 %s", len(night.Records), night.Description)
+	}
+	// 完整数据的各时段均不应有缺测，夜间跨越白天的部分不计入缺测
+	for _, segment := range result.Segments {
+		if segment.Result.Completeness != 100 || len(segment.Result.Gaps) != 0 {
+			t.Errorf("%s数据完整性错误，完整性 %.2f，缺测 %+v", segment.Name, segment.Result.Completeness, segment.Result.Gaps)
+		}
+	}
+	if night.Confidence != 1 {
+		t.Errorf("夜间置信度错误，期望 1，实际 %.2f", night.Confidence)
+	}
+
+	// 缺测 02:00 只计入夜间
+	gapped, err := NewWeatherAnalyzer(append(conditions[:2:2], conditions[3:]...))
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	gapped.SetLocation(time.FixedZone("CST", 8*3600))
+	result, err = gapped.AnalyzeSegments(segments)
+	if err != nil {
+		t.Fatalf("分段分析失败: %v", err)
+	}
+	day, night := result.Segments[0].Result, result.Segments[1].Result
+	if day.Completeness != 100 || len(day.Gaps) != 0 {
+		t.Errorf("白天数据完整性错误，完整性 %.2f，缺测 %+v", day.Completeness, day.Gaps)
+	}
+	if night.Completeness != 90 || len(night.Gaps) != 1 || night.Gaps[0].Missing != 1 {
+		t.Errorf("夜间数据完整性错误，完整性 %.2f，缺测 %+v", night.Completeness, night.Gaps)
+	}
+
+	result, err = analyzer.AnalyzeSegments(FixedPeriodSegments())
+	if err != nil {
+		t.Fatalf("分段分析失败: %v", err)
+	}
+	if result.Description != "凌晨小雨，上午多云，下午多云，夜间小雨。" {
+		t.Errorf("综合描述错误，实际 %s", result.Description)
+	}
+
+	// 没有记录的时段及没有主要天气状况的时段不写入综合描述
+	unknown := append([]WeatherCondition(nil), conditions...)
+	for i := 12; i < 18; i++ {
+		unknown[i].Condition = "外星天气"
+	}
+	unknownAnalyzer, err := NewWeatherAnalyzer(unknown)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	unknownAnalyzer.SetLogger(utils.NopLogger)
+	unknownAnalyzer.SetLocation(time.FixedZone("CST", 8*3600))
+	result, err = unknownAnalyzer.AnalyzeSegments(FixedPeriodSegments())
+	if err != nil {
+		t.Fatalf("分段分析失败: %v", err)
+	}
+	if result.Description != "凌晨小雨，上午多云，夜间小雨。" {
+		t.Errorf("综合描述错误，实际 %s", result.Description)
+	}
+
+	// 各时段均没有记录时返回数据为空错误
+	_, err = analyzer.AnalyzeSegments([]TimeSegment{{Name: "午夜", Start: 30 * time.Minute, End: 45 * time.Minute}})
+	if !errors.Is(err, utils.ErrEmptyData) {
+		t.Errorf("期望返回 ErrEmptyData，实际 %v", err)
+	}
+
+	if _, err := DayNightSegments("6点", "19:10"); err == nil {
+		t.Error("期望无法解析的时刻返回错误")
+	}
+}
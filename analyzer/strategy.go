@@ -0,0 +1,330 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// ConditionStats 单个天气状况的统计，供主导天气策略计算得分
+type ConditionStats struct {
+	// Condition 天气状况
+	Condition string
+	// Class 天气状况分类，未分类时为零值
+	Class ConditionClass
+	// BaseWeight 天气状况权重
+	BaseWeight float64
+	// AdjustedWeight 按降水量、风速调整后的权重
+	AdjustedWeight float64
+	// Duration 持续时长
+	Duration time.Duration
+	// Records 记录条数
+	Records int
+	// Order 首次出现的次序，从0开始
+	Order int
+	// FirstSeen 首次出现时间，无观测时间时为零值
+	FirstSeen time.Time
+	// LastSeen 最后出现时间，无观测时间时为零值
+	LastSeen time.Time
+	// Precipitation 累计降水量（毫米）
+	Precipitation float64
+	// MaxWindSpeed 最大风速（米/秒）
+	MaxWindSpeed float64
+}
+
+// DominanceStrategy 主导天气策略，为各天气状况计算得分，得分最高者为主要天气状况
+type DominanceStrategy interface {
+	// Name 策略名称，用于注册及按名称选择
+	Name() string
+	// Scores 计算各天气状况的得分，得分不大于0的天气状况不参与主导天气判断
+	// stats 不含空白及没有权重(无法识别)的天气状况
+	Scores(stats []ConditionStats) map[string]float64
+}
+
+// WeightedStrategy 加权时长策略(默认)：调整后权重×持续小时数
+type WeightedStrategy struct{}
+
+// Name 策略名称
+func (WeightedStrategy) Name() string {
+	return "weighted"
+}
+
+// Scores 计算各天气状况的得分
+func (WeightedStrategy) Scores(stats []ConditionStats) map[string]float64 {
+	scores := make(map[string]float64, len(stats))
+	for _, s := range stats {
+		scores[s.Condition] = s.AdjustedWeight * s.Duration.Hours()
+	}
+	return scores
+}
+
+// DurationStrategy 持续时长策略：仅按持续小时数判断，不考虑权重
+type DurationStrategy struct{}
+
+// Name 策略名称
+func (DurationStrategy) Name() string {
+	return "duration"
+}
+
+// Scores 计算各天气状况的得分
+func (DurationStrategy) Scores(stats []ConditionStats) map[string]float64 {
+	scores := make(map[string]float64, len(stats))
+	for _, s := range stats {
+		scores[s.Condition] = s.Duration.Hours()
+	}
+	return scores
+}
+
+// SeverityFirstStrategy 极端优先策略：出现任何极端天气时由极端天气胜出，极端天气之间及其余天气之间按加权时长比较
+type SeverityFirstStrategy struct {
+	// Threshold 极端天气的最低权重，为0时使用0.95
+	Threshold float64
+}
+
+// Name 策略名称
+func (SeverityFirstStrategy) Name() string {
+	return "severity-first"
+}
+
+// Scores 计算各天气状况的得分，极端天气的得分额外加上全部非极端天气的得分之和
+func (s SeverityFirstStrategy) Scores(stats []ConditionStats) map[string]float64 {
+	threshold := s.Threshold
+	if threshold == 0 {
+		threshold = 0.95
+	}
+	scores := WeightedStrategy{}.Scores(stats)
+	var ordinary float64
+	for _, st := range stats {
+		if st.BaseWeight < threshold {
+			ordinary += scores[st.Condition]
+		}
+	}
+	for _, st := range stats {
+		if st.BaseWeight >= threshold && scores[st.Condition] > 0 {
+			scores[st.Condition] += ordinary
+		}
+	}
+	return scores
+}
+
+// scoringStrategy 按自定义评分函数计算得分的策略
+type scoringStrategy struct {
+	name  string
+	score func(ConditionStats) float64
+}
+
+// NewScoringStrategy 创建按自定义评分函数计算得分的策略
+func NewScoringStrategy(name string, score func(ConditionStats) float64) DominanceStrategy {
+	return &scoringStrategy{name: name, score: score}
+}
+
+func (s *scoringStrategy) Name() string {
+	return s.name
+}
+
+func (s *scoringStrategy) Scores(stats []ConditionStats) map[string]float64 {
+	scores := make(map[string]float64, len(stats))
+	for _, st := range stats {
+		scores[st.Condition] = s.score(st)
+	}
+	return scores
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]DominanceStrategy{
+		WeightedStrategy{}.Name():      WeightedStrategy{},
+		DurationStrategy{}.Name():      DurationStrategy{},
+		SeverityFirstStrategy{}.Name(): SeverityFirstStrategy{},
+	}
+)
+
+// RegisterDominanceStrategy 注册主导天气策略，名称不能为空且不能与已注册策略重复
+func RegisterDominanceStrategy(strategy DominanceStrategy) error {
+	if strategy == nil || strategy.Name() == "" {
+		return &utils.WeatherError{
+			Code:    utils.ErrInvalidInput,
+			Message: "主导天气策略及其名称不能为空",
+		}
+	}
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if _, exists := strategies[strategy.Name()]; exists {
+		return &utils.WeatherError{
+			Code:    utils.ErrInvalidInput,
+			Message: fmt.Sprintf("主导天气策略已注册: %s", strategy.Name()),
+			Value:   strategy.Name(),
+		}
+	}
+	strategies[strategy.Name()] = strategy
+	return nil
+}
+
+// unregisterDominanceStrategy 移除已注册的主导天气策略，供测试清理使用
+func unregisterDominanceStrategy(name string) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	delete(strategies, name)
+}
+
+// LookupDominanceStrategy 按名称查找已注册的主导天气策略
+func LookupDominanceStrategy(name string) (DominanceStrategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	strategy, ok := strategies[name]
+	return strategy, ok
+}
+
+// DominanceStrategies 已注册的主导天气策略名称，按名称排序
+func DominanceStrategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildConditionStats 按记录统计各天气状况，derive 返回记录另外计入的推导天气状况(如“热”)，为空时不计入
+// 结果按首次出现的次序排列
+func buildConditionStats(records []AnalyzedRecord, baseWeights, adjustedWeights map[string]float64, derive func(AnalyzedRecord) string) []ConditionStats {
+	index := make(map[string]int)
+	var stats []ConditionStats
+	add := func(condition string, r AnalyzedRecord) {
+		i, ok := index[condition]
+		if !ok {
+			class, _ := ClassifyCondition(condition)
+			i = len(stats)
+			index[condition] = i
+			stats = append(stats, ConditionStats{
+				Condition:      condition,
+				Class:          class,
+				BaseWeight:     baseWeights[condition],
+				AdjustedWeight: adjustedWeights[condition],
+				Order:          i,
+				FirstSeen:      r.At,
+			})
+		}
+		s := &stats[i]
+		s.Duration += r.Duration
+		s.Records++
+		s.LastSeen = r.At
+		s.Precipitation += r.Precipitation
+		if r.WindSpeed > s.MaxWindSpeed {
+			s.MaxWindSpeed = r.WindSpeed
+		}
+	}
+	for _, r := range records {
+		add(r.Condition, r)
+		if derived := derive(r); derived != "" {
+			add(derived, r)
+		}
+	}
+	return stats
+}
+
+// rankableStats 参与主导天气判断的天气状况，排除空白及没有权重(无法识别)的天气状况
+// 各主导天气策略只为这些天气状况计算得分
+func rankableStats(stats []ConditionStats, weights map[string]float64) []ConditionStats {
+	var rankable []ConditionStats
+	for _, s := range stats {
+		if strings.TrimSpace(s.Condition) == "" {
+			continue
+		}
+		if _, ok := weights[s.Condition]; !ok {
+			continue
+		}
+		rankable = append(rankable, s)
+	}
+	return rankable
+}
+
+// rankConditions 按得分由高到低排列天气状况，得分相同时依次按权重(严重程度)、持续时长、首次出现次序排列，保证结果稳定
+func rankConditions(stats []ConditionStats, scores map[string]float64) []ConditionStats {
+	ranked := append([]ConditionStats(nil), stats...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if sa, sb := scores[a.Condition], scores[b.Condition]; sa != sb {
+			return sa > sb
+		}
+		if a.BaseWeight != b.BaseWeight {
+			return a.BaseWeight > b.BaseWeight
+		}
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.Order < b.Order
+	})
+	return ranked
+}
+
+// dominanceConfidence 主要天气状况的置信度(0-1)：数据完整性×(0.5+0.5×领先幅度占主要天气状况得分的比例)
+// 只有一种天气状况时领先比例按1计，没有主要天气状况时为0
+func dominanceConfidence(ranked []ConditionStats, scores map[string]float64, dominant string, completeness float64) float64 {
+	dominantScore := scores[dominant]
+	if dominant == "" || dominantScore <= 0 {
+		return 0
+	}
+	ratio := 1.0
+	for _, s := range ranked {
+		if s.Condition != dominant {
+			ratio = math.Max(0, math.Min(1, (dominantScore-scores[s.Condition])/dominantScore))
+			break
+		}
+	}
+	return completeness / 100 * (0.5 + 0.5*ratio)
+}
+
+// RankedCondition 按得分排列的天气状况
+type RankedCondition struct {
+	// Condition 天气状况
+	Condition string
+	// Share 得分占全部得分的比例（%）
+	Share float64
+	// Score 主导天气策略计算的得分
+	Score float64
+	// Duration 持续时长
+	Duration time.Duration
+	// FirstSeen 首次出现时间，无观测时间时为零值
+	FirstSeen time.Time
+	// LastSeen 最后出现时间，无观测时间时为零值
+	LastSeen time.Time
+}
+
+// rankOtherConditions 主要天气状况以外得分占比不低于 minShare(%) 的天气状况，按排名先后最多取 maxCount 个，maxCount 为0时不限
+func rankOtherConditions(ranked []ConditionStats, scores map[string]float64, dominant string, minShare float64, maxCount int) []RankedCondition {
+	var total float64
+	for _, s := range ranked {
+		total += scores[s.Condition]
+	}
+	if total <= 0 {
+		return nil
+	}
+	var others []RankedCondition
+	for _, s := range ranked {
+		score := scores[s.Condition]
+		share := score / total * 100
+		if s.Condition == dominant || score <= 0 || share < minShare {
+			continue
+		}
+		if maxCount > 0 && len(others) >= maxCount {
+			break
+		}
+		others = append(others, RankedCondition{
+			Condition: s.Condition,
+			Share:     share,
+			Score:     score,
+			Duration:  s.Duration,
+			FirstSeen: s.FirstSeen,
+			LastSeen:  s.LastSeen,
+		})
+	}
+	return others
+}
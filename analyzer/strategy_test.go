@@ -0,0 +1,278 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func strategyTestConditions() []WeatherCondition {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 10; hour++ {
+		c := WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 24.0,
+			Condition:   "晴",
+			Humidity:    60.0,
+			WindSpeed:   2.0,
+		}
+		switch {
+		case hour == 9:
+			c.Condition = "强雷阵雨"
+			c.Precipitation = 20.0
+		case hour >= 6:
+			c.Condition = "阴"
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
+
+func TestDominanceStrategies(t *testing.T) {
+	analyzer, err := NewWeatherAnalyzer(strategyTestConditions())
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	cases := []struct {
+		strategy DominanceStrategy
+		expected string
+	}{
+		// 晴 6h×0.35=2.1，阴 3h×0.65=1.95，强雷阵雨 1h×0.97≈1.16
+		{WeightedStrategy{}, "晴"},
+		{DurationStrategy{}, "晴"},
+		{SeverityFirstStrategy{}, "强雷阵雨"},
+		{NewScoringStrategy("base-weight", func(s ConditionStats) float64 { return s.BaseWeight }), "强雷阵雨"},
+		{NewScoringStrategy("cloudy", func(s ConditionStats) float64 {
+			if s.Class.Family == FamilySky {
+				return s.Duration.Hours() * s.BaseWeight * float64(s.Class.Intensity)
+			}
+			return 0
+		}), "阴"},
+	}
+	for _, c := range cases {
+		analyzer.SetDominanceStrategy(c.strategy)
+		result, err := analyzer.Analyze()
+		if err != nil {
+			t.Fatalf("分析天气状况失败: %v", err)
+		}
+		if result.DominantCondition != c.expected || result.DominanceStrategy != c.strategy.Name() {
+			t.Errorf("策略 %s 主要天气状况错误，期望 %s，实际 %s", c.strategy.Name(), c.expected, result.DominantCondition)
+		}
+	}
+
+	analyzer.SetDominanceStrategy(nil)
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	stats := result.ConditionStats
+	if len(stats) != 3 || stats[0].Condition != "晴" || stats[2].Records != 1 || stats[2].FirstSeen.Hour() != 9 {
+		t.Errorf("天气状况统计错误: %+v", stats)
+	}
+}
+
+func TestRegisterDominanceStrategy(t *testing.T) {
+	strategy := NewScoringStrategy("test-precipitation", func(s ConditionStats) float64 { return s.Precipitation })
+	if err := RegisterDominanceStrategy(strategy); err != nil {
+		t.Fatalf("注册主导天气策略失败: %v", err)
+	}
+	t.Cleanup(func() { unregisterDominanceStrategy(strategy.Name()) })
+	if err := RegisterDominanceStrategy(strategy); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("期望重复注册返回错误，实际 %v", err)
+	}
+	if err := RegisterDominanceStrategy(NewScoringStrategy("", nil)); err == nil {
+		t.Error("期望空名称返回错误")
+	}
+
+	analyzer, err := NewWeatherAnalyzer(strategyTestConditions())
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	if err := analyzer.SetDominanceStrategyByName("test-precipitation"); err != nil {
+		t.Fatalf("按名称设置策略失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.DominantCondition != "强雷阵雨" {
+		t.Errorf("自定义策略主要天气状况错误，实际 %s", result.DominantCondition)
+	}
+	if err := analyzer.SetDominanceStrategyByName("unknown"); err == nil {
+		t.Error("期望未注册的策略返回错误")
+	}
+}
+
+func TestDominanceTieBreak(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour, condition := range []string{"多云", "多云", "阴", "阴"} {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 22.0,
+			Condition:   condition,
+			Humidity:    70.0,
+			WindSpeed:   3.0,
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetDominanceStrategy(DurationStrategy{})
+
+	// 多云与阴持续时长相同，应始终按严重程度取阴
+	for i := 0; i < 50; i++ {
+		result, err := analyzer.Analyze()
+		if err != nil {
+			t.Fatalf("分析天气状况失败: %v", err)
+		}
+		if result.DominantCondition != "阴" {
+			t.Fatalf("第%d次分析主导天气状况错误，期望 阴，实际 %s", i+1, result.DominantCondition)
+		}
+		if math.Abs(result.Confidence-0.5) > 1e-9 {
+			t.Fatalf("得分相同时置信度错误，期望 0.5，实际 %.3f", result.Confidence)
+		}
+	}
+}
+
+func TestRankConditions(t *testing.T) {
+	stats := []ConditionStats{
+		{Condition: "多云", BaseWeight: 0.55, Duration: 2 * time.Hour, Order: 0},
+		{Condition: "晴", BaseWeight: 0.5, Duration: 3 * time.Hour, Order: 1},
+		{Condition: "少云", BaseWeight: 0.5, Duration: 3 * time.Hour, Order: 2},
+		{Condition: "阴", BaseWeight: 0.65, Duration: time.Hour, Order: 3},
+	}
+	scores := map[string]float64{"多云": 1, "晴": 1, "少云": 1, "阴": 2}
+	ranked := rankConditions(stats, scores)
+	expected := []string{"阴", "多云", "晴", "少云"}
+	for i, s := range ranked {
+		if s.Condition != expected[i] {
+			t.Errorf("第%d名错误，期望 %s，实际 %s", i+1, expected[i], s.Condition)
+		}
+	}
+
+	if c := dominanceConfidence(ranked, scores, "阴", 100); math.Abs(c-0.75) > 1e-9 {
+		t.Errorf("置信度错误，期望 0.75，实际 %.3f", c)
+	}
+	if c := dominanceConfidence(ranked[:1], scores, "阴", 80); math.Abs(c-0.8) > 1e-9 {
+		t.Errorf("单一天气状况置信度错误，期望 0.8，实际 %.3f", c)
+	}
+	if c := dominanceConfidence(ranked, scores, "", 100); c != 0 {
+		t.Errorf("无主要天气状况时置信度应为0，实际 %.3f", c)
+	}
+}
+
+func TestOtherConditions(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour, condition := range []string{"多云", "多云", "多云", "多云", "阴", "阴", "阴", "晴", "晴", "少云"} {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 22.0,
+			Condition:   condition,
+			Humidity:    70.0,
+			WindSpeed:   3.0,
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetDominanceStrategy(DurationStrategy{})
+	analyzer.SetOtherConditionMinShare(15)
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.OtherConditions) != 2 {
+		t.Fatalf("其他重要天气状况数量错误，期望 2，实际 %d", len(result.OtherConditions))
+	}
+	first, second := result.OtherConditions[0], result.OtherConditions[1]
+	if first.Condition != "阴" || second.Condition != "晴" {
+		t.Errorf("其他重要天气状况排序错误，期望 阴、晴，实际 %s、%s", first.Condition, second.Condition)
+	}
+	if math.Abs(first.Share-30) > 1e-9 || first.Duration != 3*time.Hour {
+		t.Errorf("阴的占比或时长错误，期望 30%%、3h，实际 %.1f%%、%v", first.Share, first.Duration)
+	}
+	if first.FirstSeen.Hour() != 4 || first.LastSeen.Hour() != 6 {
+		t.Errorf("阴的出现时间错误，期望 04:00~06:00，实际 %v~%v", first.FirstSeen, first.LastSeen)
+	}
+	if !strings.Contains(result.Description, "期间还出现阴、晴。") {
+		t.Errorf("描述应按排名列出其他天气状况，实际 %s", result.Description)
+	}
+
+	analyzer.SetMaxOtherConditions(1)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.OtherConditions) != 1 || result.OtherConditions[0].Condition != "阴" {
+		t.Errorf("限制数量后其他重要天气状况错误，实际 %v", result.OtherConditions)
+	}
+}
+
+func TestUnrankedConditions(t *testing.T) {
+	conditions := strategyTestConditions()
+	for i := 0; i < 6; i++ {
+		conditions[i].Condition = "外星天气"
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLogger(utils.NopLogger)
+	strategies := []DominanceStrategy{
+		WeightedStrategy{},
+		DurationStrategy{},
+		SeverityFirstStrategy{},
+		NewScoringStrategy("records", func(s ConditionStats) float64 { return float64(s.Records) }),
+	}
+	for _, strategy := range strategies {
+		analyzer.SetDominanceStrategy(strategy)
+		result, err := analyzer.Analyze()
+		if err != nil {
+			t.Fatalf("分析天气状况失败: %v", err)
+		}
+		if result.DominantCondition == "外星天气" {
+			t.Errorf("策略 %s: 无法识别的天气状况不应成为主要天气状况", strategy.Name())
+		}
+		if _, ok := result.ConditionWeights["外星天气"]; ok {
+			t.Errorf("策略 %s: 无法识别的天气状况不应参与评分", strategy.Name())
+		}
+		if strings.Contains(result.Description, "外星天气为主") || strings.Contains(result.Description, "还出现外星天气") {
+			t.Errorf("策略 %s: 描述不应以无法识别的天气状况为主: %s", strategy.Name(), result.Description)
+		}
+	}
+}
+
+func TestBlankConditionsNotRanked(t *testing.T) {
+	conditions := strategyTestConditions()
+	for i := range conditions {
+		conditions[i].Condition = ""
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLogger(utils.NopLogger)
+	analyzer.SetInferenceMode(InferOff)
+	for _, strategy := range []DominanceStrategy{WeightedStrategy{}, DurationStrategy{}} {
+		analyzer.SetDominanceStrategy(strategy)
+		result, err := analyzer.Analyze()
+		if err != nil {
+			t.Fatalf("分析天气状况失败: %v", err)
+		}
+		if result.DominantCondition != "" || len(result.ConditionWeights) != 0 {
+			t.Errorf("策略 %s: 空白天气状况不应参与主导天气判断，实际 %q %v",
+				strategy.Name(), result.DominantCondition, result.ConditionWeights)
+		}
+		if strings.Contains(result.Description, "以为主") {
+			t.Errorf("策略 %s: 描述错误: %s", strategy.Name(), result.Description)
+		}
+	}
+}
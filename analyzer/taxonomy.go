@@ -0,0 +1,147 @@
+package analyzer
+
+// WeatherFamily 天气大类
+type WeatherFamily string
+
+const (
+	// FamilySky 晴云类：晴、少云、晴间多云、多云、阴
+	FamilySky WeatherFamily = "晴云"
+	// FamilyRain 降雨类
+	FamilyRain WeatherFamily = "雨"
+	// FamilySnow 降雪类，含雨夹雪等雨雪混合天气
+	FamilySnow WeatherFamily = "雪"
+	// FamilyFog 雾类
+	FamilyFog WeatherFamily = "雾"
+	// FamilyHaze 霾类
+	FamilyHaze WeatherFamily = "霾"
+	// FamilyDust 沙尘类
+	FamilyDust WeatherFamily = "沙尘"
+	// FamilyTemperature 温度类：热、冷
+	FamilyTemperature WeatherFamily = "温度"
+)
+
+// ConditionClass 天气状况分类：大类 → 子类 → 强度
+type ConditionClass struct {
+	// Family 大类，如 雨
+	Family WeatherFamily
+	// Subtype 子类，如 阵雨、雷阵雨
+	Subtype string
+	// Intensity 强度，同一大类内数值越大越强，0表示未指明强度(如 雨、阵雨)
+	Intensity int
+}
+
+// conditionTaxonomy 天气状况分类表
+var conditionTaxonomy = map[string]ConditionClass{
+	"晴":    {FamilySky, "晴", 1},
+	"少云":   {FamilySky, "晴", 2},
+	"晴间多云": {FamilySky, "晴", 3},
+	"多云":   {FamilySky, "多云", 4},
+	"阴":    {FamilySky, "阴", 5},
+
+	"雨":        {FamilyRain, "雨", 0},
+	"毛毛雨/细雨":   {FamilyRain, "雨", 1},
+	"小雨":       {FamilyRain, "雨", 1},
+	"小到中雨":     {FamilyRain, "雨", 2},
+	"中雨":       {FamilyRain, "雨", 2},
+	"中到大雨":     {FamilyRain, "雨", 3},
+	"大雨":       {FamilyRain, "雨", 3},
+	"大到暴雨":     {FamilyRain, "雨", 4},
+	"暴雨":       {FamilyRain, "雨", 4},
+	"暴雨到大暴雨":   {FamilyRain, "雨", 5},
+	"大暴雨":      {FamilyRain, "雨", 5},
+	"大暴雨到特大暴雨": {FamilyRain, "雨", 6},
+	"特大暴雨":     {FamilyRain, "雨", 6},
+	"极端降雨":     {FamilyRain, "雨", 6},
+	"阵雨":       {FamilyRain, "阵雨", 0},
+	"强阵雨":      {FamilyRain, "阵雨", 4},
+	"雷阵雨":      {FamilyRain, "雷阵雨", 0},
+	"强雷阵雨":     {FamilyRain, "雷阵雨", 4},
+	"雷阵雨伴有冰雹":  {FamilyRain, "雷阵雨", 5},
+	"冻雨":       {FamilyRain, "冻雨", 0},
+
+	"雪":    {FamilySnow, "雪", 0},
+	"小雪":   {FamilySnow, "雪", 1},
+	"小到中雪": {FamilySnow, "雪", 2},
+	"中雪":   {FamilySnow, "雪", 2},
+	"中到大雪": {FamilySnow, "雪", 3},
+	"大雪":   {FamilySnow, "雪", 3},
+	"大到暴雪": {FamilySnow, "雪", 4},
+	"暴雪":   {FamilySnow, "雪", 4},
+	"阵雪":   {FamilySnow, "阵雪", 0},
+	"雨夹雪":  {FamilySnow, "雨夹雪", 0},
+	"雨雪天气": {FamilySnow, "雨夹雪", 0},
+	"阵雨夹雪": {FamilySnow, "雨夹雪", 0},
+
+	"薄雾":   {FamilyFog, "雾", 1},
+	"雾":    {FamilyFog, "雾", 2},
+	"大雾":   {FamilyFog, "雾", 3},
+	"浓雾":   {FamilyFog, "雾", 4},
+	"强浓雾":  {FamilyFog, "雾", 5},
+	"特强浓雾": {FamilyFog, "雾", 6},
+
+	"霾":   {FamilyHaze, "霾", 1},
+	"中度霾": {FamilyHaze, "霾", 2},
+	"重度霾": {FamilyHaze, "霾", 3},
+	"严重霾": {FamilyHaze, "霾", 4},
+
+	"浮尘":   {FamilyDust, "浮尘", 1},
+	"扬沙":   {FamilyDust, "扬沙", 2},
+	"沙尘暴":  {FamilyDust, "沙尘暴", 3},
+	"强沙尘暴": {FamilyDust, "沙尘暴", 4},
+
+	"热": {FamilyTemperature, "热", 0},
+	"冷": {FamilyTemperature, "冷", 0},
+}
+
+// ClassifyCondition 获取天气状况的分类，天气状况需为规范化后的名称
+func ClassifyCondition(condition string) (ConditionClass, bool) {
+	class, ok := conditionTaxonomy[condition]
+	return class, ok
+}
+
+// familyWeights 按大类汇总天气状况的加权时长，未分类的天气状况不计入
+func familyWeights(conditionWeights map[string]float64) map[WeatherFamily]float64 {
+	weights := make(map[WeatherFamily]float64)
+	for condition, weight := range conditionWeights {
+		if class, ok := ClassifyCondition(condition); ok {
+			weights[class.Family] += weight
+		}
+	}
+	return weights
+}
+
+// dominantByFamily 按大类→子类→天气状况逐级选出加权时长最大者，返回主导大类及其代表天气状况
+// ranked 为按得分排列的天气状况，子类内取排在最前者
+func dominantByFamily(conditionWeights map[string]float64, ranked []ConditionStats) (WeatherFamily, string) {
+	family := maxKey(familyWeights(conditionWeights))
+	if family == "" {
+		return "", ""
+	}
+
+	subtypes := make(map[string]float64)
+	for condition, weight := range conditionWeights {
+		if class, ok := ClassifyCondition(condition); ok && class.Family == family {
+			subtypes[class.Subtype] += weight
+		}
+	}
+	subtype := maxKey(subtypes)
+
+	for _, s := range ranked {
+		if class, ok := ClassifyCondition(s.Condition); ok && class.Family == family && class.Subtype == subtype {
+			return family, s.Condition
+		}
+	}
+	return family, ""
+}
+
+// maxKey 取值最大的键，值相同时取较小的键以保证结果稳定，没有正值时返回零值
+func maxKey[K ~string](values map[K]float64) K {
+	var best K
+	var bestValue float64
+	for key, value := range values {
+		if value > bestValue || (value == bestValue && value > 0 && key < best) {
+			best, bestValue = key, value
+		}
+	}
+	return best
+}
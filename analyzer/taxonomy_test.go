@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassifyCondition(t *testing.T) {
+	class, ok := ClassifyCondition("强雷阵雨")
+	if !ok || class.Family != FamilyRain || class.Subtype != "雷阵雨" || class.Intensity != 4 {
+		t.Errorf("强雷阵雨分类错误: %+v", class)
+	}
+	for condition := range conditionTaxonomy {
+		if _, ok := defaultConditionWeights()[condition]; !ok {
+			t.Errorf("分类表中的 %s 没有默认权重", condition)
+		}
+	}
+	for condition := range defaultConditionWeights() {
+		if _, ok := ClassifyCondition(condition); !ok {
+			t.Errorf("默认权重中的 %s 没有分类", condition)
+		}
+	}
+}
+
+func TestFamilyAggregation(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour, condition := range []string{"多云", "多云", "多云", "多云", "多云", "多云", "小雨", "小雨", "中雨", "中雨", "阵雨", "阵雨"} {
+		c := WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 24.0,
+			Condition:   condition,
+			Humidity:    80.0,
+			WindSpeed:   2.0,
+		}
+		if hour >= 6 {
+			c.Precipitation = 0.5
+		}
+		conditions = append(conditions, c)
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.DominantCondition != "多云" || result.DominantFamily != FamilySky {
+		t.Errorf("未开启大类汇总时主要天气错误，实际 %s(%s)", result.DominantCondition, result.DominantFamily)
+	}
+	if result.FamilyWeights[FamilyRain] <= result.FamilyWeights[FamilySky] {
+		t.Errorf("大类加权时长错误: %v", result.FamilyWeights)
+	}
+
+	analyzer.SetFamilyAggregation(true)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	// 降雨类合计超过多云，子类“雨”(小雨、中雨)超过阵雨，再细化为加权时长最大的小雨
+	if result.DominantFamily != FamilyRain || result.DominantCondition != "小雨" {
+		t.Errorf("开启大类汇总时主要天气错误，实际 %s(%s)", result.DominantCondition, result.DominantFamily)
+	}
+}
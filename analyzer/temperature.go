@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// temperatureStats 温度统计
+type temperatureStats struct {
+	min, max    AnalyzedRecord
+	stdDev      float64
+	maxChange   float64
+	maxChangeAt time.Time
+	hasChange   bool
+}
+
+// analyzeTemperature 统计最高、最低温度及出现时间、按时长加权的标准差和最大小时变化
+// 极值相同时取最先出现的记录；有观测时间时小时变化为与1小时内各记录的最大温差，否则为相邻记录温差
+func analyzeTemperature(records []AnalyzedRecord, timed bool, avg, totalHours float64) temperatureStats {
+	stats := temperatureStats{min: records[0], max: records[0]}
+	var variance float64
+	for i, r := range records {
+		if r.Temperature < stats.min.Temperature {
+			stats.min = r
+		}
+		if r.Temperature > stats.max.Temperature {
+			stats.max = r
+		}
+		variance += (r.Temperature - avg) * (r.Temperature - avg) * r.Duration.Hours()
+
+		for j := i - 1; j >= 0; j-- {
+			if timed && r.At.Sub(records[j].At) > time.Hour {
+				break
+			}
+			change := r.Temperature - records[j].Temperature
+			if !stats.hasChange || math.Abs(change) > math.Abs(stats.maxChange) {
+				stats.maxChange, stats.maxChangeAt, stats.hasChange = change, r.At, true
+			}
+			if !timed {
+				break
+			}
+		}
+	}
+	if totalHours > 0 {
+		stats.stdDev = math.Sqrt(variance / totalHours)
+	}
+	return stats
+}
+
+// phrase 气温范围描述，如“气温12~25°C”
+func (s temperatureStats) phrase() string {
+	low, high := math.Round(s.min.Temperature), math.Round(s.max.Temperature)
+	if low == high {
+		return fmt.Sprintf("气温%.0f°C", high)
+	}
+	return fmt.Sprintf("气温%.0f~%.0f°C", low, high)
+}
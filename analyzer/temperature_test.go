@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTemperatureStatistics(t *testing.T) {
+	temperatures := []float64{14.0, 12.2, 13.0, 19.0, 25.0, 24.6, 20.0}
+	var conditions []WeatherCondition
+	for i, temperature := range temperatures {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", i*2),
+			Temperature: temperature,
+			Condition:   "晴",
+			Humidity:    50.0,
+			WindSpeed:   2.0,
+		})
+	}
+	// 30分钟后的加密观测
+	conditions = append(conditions, WeatherCondition{
+		Time: "2024-07-01T12:30:00+08:00", Temperature: 16.0, Condition: "晴", Humidity: 50.0, WindSpeed: 2.0,
+	})
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	if result.MinTemperature != 12.2 || result.MinTemperatureIndex != 2 || result.MinTemperatureTime.Hour() != 2 {
+		t.Errorf("最低温度错误: %.1f°C，记录 %d，时间 %v", result.MinTemperature, result.MinTemperatureIndex, result.MinTemperatureTime)
+	}
+	if result.MaxTemperature != 25.0 || result.MaxTemperatureIndex != 5 || result.MaxTemperatureTime.Hour() != 8 {
+		t.Errorf("最高温度错误: %.1f°C，记录 %d，时间 %v", result.MaxTemperature, result.MaxTemperatureIndex, result.MaxTemperatureTime)
+	}
+	if math.Abs(result.TemperatureRange-12.8) > 1e-9 {
+		t.Errorf("温度日较差错误，期望 12.8，实际 %.1f", result.TemperatureRange)
+	}
+	// 间隔2小时的记录不计入小时变化，最大小时变化为12:00至12:30的降温
+	if result.MaxHourlyTemperatureChange != -4.0 || result.MaxHourlyTemperatureChangeTime.Minute() != 30 {
+		t.Errorf("最大小时温度变化错误，期望 -4.0，实际 %.1f", result.MaxHourlyTemperatureChange)
+	}
+	if result.TemperatureStdDev <= 0 {
+		t.Errorf("温度标准差错误，实际 %.2f", result.TemperatureStdDev)
+	}
+	if !strings.Contains(result.Description, "气温12~25°C") {
+		t.Errorf("天气描述错误，实际 %s", result.Description)
+	}
+}
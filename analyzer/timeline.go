@@ -0,0 +1,290 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// timeLayouts 支持的观测时间格式
+var timeLayouts = []string{
+	time.RFC3339,             // ISO 8601，如 2024-01-01T08:00:00+08:00
+	"2006-01-02T15:04Z07:00", // 和风天气，如 2024-01-01T08:00+08:00
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseTime 解析观测时间，不含时区偏移的时间按 loc 解析，loc 为 nil 时使用 time.Local
+func ParseTime(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, &utils.WeatherError{
+		Code:    utils.ErrInvalidTime,
+		Message: fmt.Sprintf("无法解析观测时间: %s", value),
+		Field:   "Time",
+		Value:   value,
+	}
+}
+
+// DuplicatePolicy 重复时间记录处理策略
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeep 保留全部重复记录，仅在结果中报告(默认)
+	DuplicateKeep DuplicatePolicy = iota
+	// DuplicateKeepFirst 仅保留最先出现的记录
+	DuplicateKeepFirst
+	// DuplicateKeepLast 仅保留最后出现的记录
+	DuplicateKeepLast
+	// DuplicateMerge 合并为一条记录，数值取平均，天气状况取权重最高者
+	DuplicateMerge
+)
+
+// AnalyzedRecord 参与分析的单条记录
+type AnalyzedRecord struct {
+	WeatherCondition
+	// Index 原始记录序号，从1开始；合并记录为首条记录序号
+	Index int
+	// RawCondition 规范化前的原始天气状况文本
+	RawCondition string
+	// At 解析后的观测时间，无法解析时为零值
+	At time.Time
+	// Filled 是否为缺测填补生成的记录，填补记录的 Index 为0
+	Filled bool
+	// Inferred 天气状况是否由数值推断得出
+	Inferred bool
+	// Duration 记录代表的时长，即至下一条记录的间隔；无观测时间时按1小时计
+	Duration time.Duration
+	// WindForce 风力等级(蒲福风级)
+	WindForce int
+	// Comfort 体感指标
+	Comfort ThermalComfort
+	// Intensity 实测降水强度等级，如 中雨、大雪，无降水或降水量不足0.1毫米时为空
+	Intensity string
+}
+
+// timeline 按时间整理后的记录
+type timeline struct {
+	records []AnalyzedRecord
+	// 全部记录均有有效观测时间，此时记录已按时间排序
+	timed bool
+	// 与前面记录时间重复的记录序号
+	duplicates []int
+	flags      []ValidationIssue
+}
+
+// newTimeline 解析观测时间并按时间排序，存在无法解析的时间时保持原始顺序
+func newTimeline(records []AnalyzedRecord, loc *time.Location) *timeline {
+	tl := &timeline{records: records, timed: true}
+	for i := range tl.records {
+		r := &tl.records[i]
+		if strings.TrimSpace(r.Time) == "" {
+			tl.timed = false
+			continue
+		}
+		t, err := ParseTime(r.Time, loc)
+		if err != nil {
+			tl.timed = false
+			tl.flags = append(tl.flags, ValidationIssue{
+				Index:   r.Index,
+				Field:   "Time",
+				Value:   r.Time,
+				Code:    utils.ErrInvalidTime,
+				Rule:    "观测时间需为ISO 8601或 2006-01-02 15:04 格式",
+				Message: fmt.Sprintf("无法解析观测时间: %s", r.Time),
+			})
+			continue
+		}
+		r.At = t
+	}
+	if !tl.timed {
+		return tl
+	}
+
+	sort.SliceStable(tl.records, func(i, j int) bool {
+		return tl.records[i].At.Before(tl.records[j].At)
+	})
+	for i := 1; i < len(tl.records); i++ {
+		if tl.records[i].At.Equal(tl.records[i-1].At) {
+			tl.duplicates = append(tl.duplicates, tl.records[i].Index)
+		}
+	}
+	sort.Ints(tl.duplicates)
+	return tl
+}
+
+// dedupe 按策略处理重复时间记录，weights 用于合并时选择天气状况
+func (tl *timeline) dedupe(policy DuplicatePolicy, weights map[string]float64) {
+	if !tl.timed || len(tl.duplicates) == 0 || policy == DuplicateKeep {
+		return
+	}
+	deduped := make([]AnalyzedRecord, 0, len(tl.records))
+	for start := 0; start < len(tl.records); {
+		end := start + 1
+		for end < len(tl.records) && tl.records[end].At.Equal(tl.records[start].At) {
+			end++
+		}
+		group := tl.records[start:end]
+		switch policy {
+		case DuplicateKeepFirst:
+			deduped = append(deduped, firstByIndex(group))
+		case DuplicateKeepLast:
+			deduped = append(deduped, lastByIndex(group))
+		case DuplicateMerge:
+			deduped = append(deduped, mergeRecords(group, weights))
+		}
+		start = end
+	}
+	tl.records = deduped
+}
+
+func firstByIndex(group []AnalyzedRecord) AnalyzedRecord {
+	first := group[0]
+	for _, r := range group[1:] {
+		if r.Index < first.Index {
+			first = r
+		}
+	}
+	return first
+}
+
+func lastByIndex(group []AnalyzedRecord) AnalyzedRecord {
+	last := group[0]
+	for _, r := range group[1:] {
+		if r.Index > last.Index {
+			last = r
+		}
+	}
+	return last
+}
+
+// mergeRecords 合并同一时间的多条记录
+func mergeRecords(group []AnalyzedRecord, weights map[string]float64) AnalyzedRecord {
+	merged := firstByIndex(group)
+	if len(group) == 1 {
+		return merged
+	}
+	n := float64(len(group))
+	var temperature, humidity, windSpeed, precipitation, dew float64
+	var directions []float64
+	dewCount := 0
+	for _, r := range group {
+		temperature += r.Temperature
+		humidity += r.Humidity
+		windSpeed += r.WindSpeed
+		precipitation += r.Precipitation
+		if r.DewPoint != nil {
+			dew += *r.DewPoint
+			dewCount++
+		}
+		if r.WindDirection != nil {
+			directions = append(directions, *r.WindDirection)
+		}
+		if weights[r.Condition] > weights[merged.Condition] {
+			merged.Condition = r.Condition
+		}
+	}
+	merged.Temperature = temperature / n
+	merged.Humidity = humidity / n
+	merged.WindSpeed = windSpeed / n
+	merged.Precipitation = precipitation / n
+	if dewCount > 0 {
+		avg := dew / float64(dewCount)
+		merged.DewPoint = &avg
+	}
+	merged.WindDirection = meanWindDirection(directions)
+	return merged
+}
+
+// temporalFlags 检查相邻记录的温度变化，有观测时间时按实际间隔折算为每小时变化
+func temporalFlags(records []AnalyzedRecord, timed bool, rules ValidationRules) []ValidationIssue {
+	var flags []ValidationIssue
+	for i := 1; i < len(records); i++ {
+		hours := 1.0
+		if timed {
+			hours = math.Max(records[i].At.Sub(records[i-1].At).Hours(), 1)
+		}
+		change := records[i].Temperature - records[i-1].Temperature
+		if math.Abs(change)/hours <= rules.MaxHourlyTemperatureChange {
+			continue
+		}
+		flags = append(flags, ValidationIssue{
+			Index:   records[i].Index,
+			Field:   "Temperature",
+			Value:   records[i].Temperature,
+			Code:    utils.ErrTemperatureJump,
+			Rule:    fmt.Sprintf("相邻两小时温度变化不超过%g°C", rules.MaxHourlyTemperatureChange),
+			Message: fmt.Sprintf("温度突变: 较上一条记录变化%+.1f°C", change),
+		})
+	}
+	return flags
+}
+
+// assignDurations 计算每条记录代表的时长
+// 间隔明显大于期望间隔及相邻间隔(缺测)时按相邻间隔计，同一时间的重复记录平分时长
+func (tl *timeline) assignDurations(interval time.Duration) {
+	if !tl.timed {
+		for i := range tl.records {
+			tl.records[i].Duration = time.Hour
+		}
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	// 按观测时间分组，计算相邻时间点的间隔
+	var starts []int
+	for i := range tl.records {
+		if i == 0 || !tl.records[i].At.Equal(tl.records[i-1].At) {
+			starts = append(starts, i)
+		}
+	}
+	intervals := make([]time.Duration, len(starts)-1)
+	for k := range intervals {
+		intervals[k] = tl.records[starts[k+1]].At.Sub(tl.records[starts[k]].At)
+	}
+	isGap := func(k int) bool {
+		neighbour := interval
+		if k > 0 {
+			neighbour = max(neighbour, intervals[k-1])
+		}
+		if k+1 < len(intervals) {
+			neighbour = max(neighbour, intervals[k+1])
+		}
+		return float64(intervals[k]) > 1.5*float64(neighbour)
+	}
+
+	for k, start := range starts {
+		var d time.Duration
+		switch {
+		case k < len(intervals) && !isGap(k):
+			d = intervals[k]
+		case k > 0 && !isGap(k-1):
+			// 末条记录或缺测前的记录，沿用前一个间隔
+			d = intervals[k-1]
+		default:
+			d = interval
+		}
+		end := len(tl.records)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		for i := start; i < end; i++ {
+			tl.records[i].Duration = d / time.Duration(end-start)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	want := time.Date(2024, 1, 1, 8, 0, 0, 0, loc)
+	for _, value := range []string{
+		"2024-01-01T08:00:00+08:00",
+		"2024-01-01T08:00+08:00",
+		"2024-01-01T00:00:00Z",
+		"2024-01-01 08:00",
+		" 2024-01-01 08:00:00 ",
+	} {
+		got, err := ParseTime(value, loc)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("解析 %q 错误，期望 %v，实际 %v", value, want, got)
+		}
+	}
+	if _, err := ParseTime("01/01/2024", loc); err == nil {
+		t.Error("期望无法解析的时间返回错误")
+	}
+}
+
+func TestAnalyzeTimeline(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01T02:00+08:00", Temperature: 22.0, Condition: "多云", Humidity: 60.0},
+		{Time: "2024-01-01 00:00", Temperature: 20.0, Condition: "晴", Humidity: 60.0},
+		{Time: "2024-01-01T01:00:00+08:00", Temperature: 21.0, Condition: "晴", Humidity: 60.0},
+		{Time: "2024-01-01 01:00", Temperature: 23.0, Condition: "阴", Humidity: 70.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetLocation(time.FixedZone("CST", 8*3600))
+
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.Records) != 4 || result.Records[0].Index != 2 || result.Records[3].Index != 1 {
+		t.Errorf("记录未按时间排序")
+	}
+	if len(result.DuplicateRecords) != 1 || result.DuplicateRecords[0] != 4 {
+		t.Errorf("重复记录错误，实际 %v", result.DuplicateRecords)
+	}
+	if result.TimeSpan != 2*time.Hour || result.StartTime.Hour() != 0 {
+		t.Errorf("时间跨度错误，实际 %v，起始 %v", result.TimeSpan, result.StartTime)
+	}
+
+	analyzer.SetDuplicatePolicy(DuplicateMerge)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.Records) != 3 {
+		t.Fatalf("合并后记录数量错误，期望 3，实际 %d", len(result.Records))
+	}
+	merged := result.Records[1]
+	if merged.Temperature != 22.0 || merged.Humidity != 65.0 || merged.Condition != "阴" {
+		t.Errorf("合并记录错误，实际 %+v", merged)
+	}
+}
+
+func TestAnalyzeDurationWeighted(t *testing.T) {
+	var conditions []WeatherCondition
+	// 00:00-00:50 每10分钟一条自动站记录
+	for minute := 0; minute < 60; minute += 10 {
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-01-01 00:%02d", minute),
+			Temperature:   10.0,
+			Condition:     "小雨",
+			Humidity:      90.0,
+			WindSpeed:     3.0,
+			Precipitation: 0.5,
+		})
+	}
+	// 01:00-03:00 逐小时记录
+	for hour := 1; hour <= 3; hour++ {
+		conditions = append(conditions, WeatherCondition{
+			Time:        fmt.Sprintf("2024-01-01 %02d:00", hour),
+			Temperature: 20.0,
+			Condition:   "多云",
+			Humidity:    60.0,
+			WindSpeed:   1.0,
+		})
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	if result.AverageTemperature != 17.5 {
+		t.Errorf("时间加权平均温度错误，期望 17.5，实际 %.2f", result.AverageTemperature)
+	}
+	if result.TotalPrecipitation != 3.0 || result.MaxPrecipitation != 3.0 {
+		t.Errorf("降水量错误，总量 %.1f，最大小时 %.1f", result.TotalPrecipitation, result.MaxPrecipitation)
+	}
+	if result.PrecipitationDuration != time.Hour || result.PrecipitationHours != 1 {
+		t.Errorf("降水持续时长错误，实际 %v", result.PrecipitationDuration)
+	}
+	if result.ConditionDurations["多云"] != 3*time.Hour {
+		t.Errorf("多云持续时长错误，实际 %v", result.ConditionDurations["多云"])
+	}
+	// 6条10分钟小雨记录只代表1小时，不应压过3小时多云
+	if result.DominantCondition != "多云" {
+		t.Errorf("主要天气状况错误，期望 多云，实际 %s", result.DominantCondition)
+	}
+}
+
+func TestThreeHourlyPrecipitation(t *testing.T) {
+	var conditions []WeatherCondition
+	// 每3小时一条记录，每条9毫米
+	for hour := 0; hour < 24; hour += 3 {
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-07-01 %02d:00", hour),
+			Temperature:   24.0,
+			Condition:     "中雨",
+			Humidity:      90.0,
+			WindSpeed:     3.0,
+			Precipitation: 9.0,
+		})
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	// 3小时9毫米折算为每小时3毫米
+	if result.MaxPrecipitation != 3.0 {
+		t.Errorf("最大小时降水量错误，期望 3.0，实际 %.1f", result.MaxPrecipitation)
+	}
+	if result.Records[0].Intensity != "中雨" {
+		t.Errorf("降水强度错误，期望 中雨，实际 %s", result.Records[0].Intensity)
+	}
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+)
+
+// maxTransitionPhraseEpisodes 生成“A转B转C”描述的最大天气段数，超过时不生成
+const maxTransitionPhraseEpisodes = 3
+
+// ConditionEpisode 天气状况持续段
+type ConditionEpisode struct {
+	// Condition 天气状况
+	Condition string
+	// Start 开始时间，无观测时间时为零值
+	Start time.Time
+	// End 结束时间，即最后一条记录的时间加其代表的时长
+	End time.Time
+	// Duration 持续时长
+	Duration time.Duration
+}
+
+// ConditionTransition 天气状况转变
+type ConditionTransition struct {
+	// Time 转变开始时间，无观测时间时为零值
+	Time time.Time
+	// From 转变前天气状况
+	From string
+	// To 转变后天气状况
+	To string
+	// Duration 转变后天气状况的持续时长
+	Duration time.Duration
+}
+
+// detectEpisodes 将连续相同天气状况的记录合并为天气段，持续时长不足 minPersistence 的短暂变化并入相邻天气段
+func detectEpisodes(records []AnalyzedRecord, minPersistence time.Duration) []ConditionEpisode {
+	var episodes []ConditionEpisode
+	for _, r := range records {
+		var end time.Time
+		if !r.At.IsZero() {
+			end = r.At.Add(r.Duration)
+		}
+		if n := len(episodes); n > 0 && episodes[n-1].Condition == r.Condition {
+			episodes[n-1].End = end
+			episodes[n-1].Duration += r.Duration
+			continue
+		}
+		episodes = append(episodes, ConditionEpisode{
+			Condition: r.Condition,
+			Start:     r.At,
+			End:       end,
+			Duration:  r.Duration,
+		})
+	}
+
+	for len(episodes) > 1 {
+		// 找出最短的短暂天气段
+		shortest := -1
+		for i, e := range episodes {
+			if e.Duration < minPersistence && (shortest < 0 || e.Duration < episodes[shortest].Duration) {
+				shortest = i
+			}
+		}
+		if shortest < 0 {
+			break
+		}
+		// 并入持续时间较长的相邻天气段，相同时并入前一段
+		target := shortest - 1
+		if shortest == 0 || (shortest+1 < len(episodes) && episodes[shortest+1].Duration > episodes[shortest-1].Duration) {
+			target = shortest + 1
+		}
+		lo, hi := min(shortest, target), max(shortest, target)
+		merged := ConditionEpisode{
+			Condition: episodes[target].Condition,
+			Start:     episodes[lo].Start,
+			End:       episodes[hi].End,
+			Duration:  episodes[lo].Duration + episodes[hi].Duration,
+		}
+		episodes = append(episodes[:lo], append([]ConditionEpisode{merged}, episodes[hi+1:]...)...)
+		episodes = coalesceEpisodes(episodes)
+	}
+	return episodes
+}
+
+// coalesceEpisodes 合并相邻的相同天气段
+func coalesceEpisodes(episodes []ConditionEpisode) []ConditionEpisode {
+	coalesced := episodes[:1]
+	for _, e := range episodes[1:] {
+		last := &coalesced[len(coalesced)-1]
+		if last.Condition == e.Condition {
+			last.End = e.End
+			last.Duration += e.Duration
+			continue
+		}
+		coalesced = append(coalesced, e)
+	}
+	return coalesced
+}
+
+// episodeTransitions 由天气段生成天气转变列表
+func episodeTransitions(episodes []ConditionEpisode) []ConditionTransition {
+	var transitions []ConditionTransition
+	for i := 1; i < len(episodes); i++ {
+		transitions = append(transitions, ConditionTransition{
+			Time:     episodes[i].Start,
+			From:     episodes[i-1].Condition,
+			To:       episodes[i].Condition,
+			Duration: episodes[i].Duration,
+		})
+	}
+	return transitions
+}
+
+// transitionPhrase 生成“A转B”或“A转B转C”描述，天气段过多或没有转变时返回空字符串
+func transitionPhrase(episodes []ConditionEpisode) string {
+	if len(episodes) < 2 || len(episodes) > maxTransitionPhraseEpisodes {
+		return ""
+	}
+	conditions := make([]string, len(episodes))
+	for i, e := range episodes {
+		conditions[i] = e.Condition
+	}
+	return strings.Join(conditions, "转")
+}
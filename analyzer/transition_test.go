@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransitions(t *testing.T) {
+	var conditions []WeatherCondition
+	for hour := 0; hour < 10; hour++ {
+		c := WeatherCondition{
+			Time:        fmt.Sprintf("2024-07-01T%02d:00:00+08:00", hour),
+			Temperature: 26.0,
+			Condition:   "晴",
+			Humidity:    60.0,
+			WindSpeed:   2.0,
+		}
+		switch {
+		case hour == 4:
+			// 短暂出现的多云应并入持续时间较长的小雨天气段
+			c.Condition = "多云"
+		case hour > 4:
+			c.Condition = "小雨"
+			c.Precipitation = 1.0
+		}
+		conditions = append(conditions, c)
+	}
+
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.TransitionText != "晴转小雨" {
+		t.Errorf("天气转变描述错误，期望 晴转小雨，实际 %s", result.TransitionText)
+	}
+	if !strings.HasPrefix(result.Description, "今日天气晴转小雨，以小雨为主") {
+		t.Errorf("天气描述错误，实际 %s", result.Description)
+	}
+	if len(result.Transitions) != 1 {
+		t.Fatalf("天气转变数量错误，期望 1，实际 %d", len(result.Transitions))
+	}
+	transition := result.Transitions[0]
+	if transition.From != "晴" || transition.To != "小雨" || transition.Duration != 6*time.Hour || transition.Time.Hour() != 4 {
+		t.Errorf("天气转变错误: %+v", transition)
+	}
+	if len(result.Episodes) != 2 || result.Episodes[0].Duration != 4*time.Hour {
+		t.Errorf("天气段错误: %+v", result.Episodes)
+	}
+
+	// 不过滤短暂变化时出现三个天气段
+	analyzer.SetTransitionMinPersistence(0)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if result.TransitionText != "晴转多云转小雨" {
+		t.Errorf("天气转变描述错误，期望 晴转多云转小雨，实际 %s", result.TransitionText)
+	}
+}
+
+func TestDetectEpisodesTooMany(t *testing.T) {
+	var records []AnalyzedRecord
+	for i, condition := range []string{"晴", "晴", "多云", "多云", "阴", "阴", "小雨", "小雨"} {
+		records = append(records, AnalyzedRecord{
+			WeatherCondition: WeatherCondition{Condition: condition},
+			Index:            i + 1,
+			Duration:         time.Hour,
+		})
+	}
+	episodes := detectEpisodes(records, 2*time.Hour)
+	if len(episodes) != 4 {
+		t.Fatalf("天气段数量错误，期望 4，实际 %d", len(episodes))
+	}
+	if phrase := transitionPhrase(episodes); phrase != "" {
+		t.Errorf("天气段过多时不应生成转变描述，实际 %s", phrase)
+	}
+}
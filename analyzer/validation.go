@@ -0,0 +1,325 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// InvalidRecordPolicy 无效记录处理策略
+type InvalidRecordPolicy int
+
+const (
+	// InvalidRecordReject 存在无效记录时返回错误(默认)
+	InvalidRecordReject InvalidRecordPolicy = iota
+	// InvalidRecordSkip 跳过无效记录，仅分析有效记录
+	InvalidRecordSkip
+	// InvalidRecordClamp 将越界数值截断到有效范围，无法截断的记录(如NaN)将被跳过
+	InvalidRecordClamp
+)
+
+// ValidationRules 数据校验规则，风向(可选)的有效范围固定为0-360度
+// 数值范围规则用于判定无效记录；合理性规则仅产生数据质量标记，不影响记录有效性
+// 零值字段不会取默认值，应从 DefaultValidationRules() 开始修改需要调整的字段
+type ValidationRules struct {
+	// 温度有效范围（摄氏度）
+	MinTemperature float64
+	MaxTemperature float64
+	// 相对湿度有效范围（%）
+	MinHumidity float64
+	MaxHumidity float64
+	// 风速上限（米/秒）
+	MaxWindSpeed float64
+	// 降水量上限（毫米）
+	MaxPrecipitation float64
+
+	// 降雪类天气的最高合理温度（摄氏度）
+	MaxSnowTemperature float64
+	// 观测露点与按温湿度推算露点的最大偏差（摄氏度）
+	MaxDewPointDeviation float64
+	// 相邻两小时温度变化上限（摄氏度）
+	MaxHourlyTemperatureChange float64
+}
+
+// DefaultValidationRules 默认数据校验规则
+func DefaultValidationRules() ValidationRules {
+	return ValidationRules{
+		MinTemperature:             -100,
+		MaxTemperature:             100,
+		MinHumidity:                0,
+		MaxHumidity:                100,
+		MaxWindSpeed:               100,
+		MaxPrecipitation:           1000,
+		MaxSnowTemperature:         6,
+		MaxDewPointDeviation:       3,
+		MaxHourlyTemperatureChange: 10,
+	}
+}
+
+// ValidationIssue 单条校验问题
+type ValidationIssue struct {
+	// Index 记录序号，从1开始
+	Index int
+	// Field 字段名，如 Temperature
+	Field string
+	// Value 字段值
+	Value any
+	// Code 错误码
+	Code utils.ErrorCode
+	// Rule 违反的规则说明
+	Rule string
+	// Message 问题描述
+	Message string
+}
+
+// ValidationReport 天气数据校验报告
+type ValidationReport struct {
+	// Total 记录总数
+	Total int
+	// Issues 导致记录无效的问题，按记录序号排列
+	Issues []ValidationIssue
+	// InvalidRecords 无效记录序号，从1开始
+	InvalidRecords []int
+	// Flags 数据质量标记，如降水与天气现象矛盾、温度突变等，不影响记录有效性
+	Flags []ValidationIssue
+}
+
+// Valid 是否全部记录有效
+func (r ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// Err 返回首个校验问题对应的错误，全部有效时返回 nil
+func (r ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	issue := r.Issues[0]
+	return &utils.WeatherError{
+		Code:    utils.ErrInvalidInput,
+		Message: fmt.Sprintf("第%d个天气数据无效，共%d个无效数据", issue.Index, len(r.InvalidRecords)),
+		Field:   issue.Field,
+		Value:   issue.Value,
+		Index:   issue.Index,
+		Err:     issue.err(),
+	}
+}
+
+func (i ValidationIssue) err() *utils.WeatherError {
+	return &utils.WeatherError{
+		Code:    i.Code,
+		Message: i.Message,
+		Field:   i.Field,
+		Value:   i.Value,
+		Index:   i.Index,
+	}
+}
+
+// fieldRange 数值字段有效范围
+type fieldRange struct {
+	field    string
+	code     utils.ErrorCode
+	min, max float64
+	format   string
+	value    func(c *WeatherCondition) *float64
+}
+
+// fieldRanges 各数值字段的有效范围
+func (r ValidationRules) fieldRanges() []fieldRange {
+	return []fieldRange{
+		{"Temperature", utils.ErrInvalidTemperature, r.MinTemperature, r.MaxTemperature, "温度数据异常: %.1f°C", func(c *WeatherCondition) *float64 { return &c.Temperature }},
+		{"Humidity", utils.ErrInvalidHumidity, r.MinHumidity, r.MaxHumidity, "湿度数据异常: %.1f%%", func(c *WeatherCondition) *float64 { return &c.Humidity }},
+		{"WindSpeed", utils.ErrInvalidWindSpeed, 0, r.MaxWindSpeed, "风速数据异常: %.1f m/s", func(c *WeatherCondition) *float64 { return &c.WindSpeed }},
+		{"Precipitation", utils.ErrInvalidPrecipitation, 0, r.MaxPrecipitation, "降水量数据异常: %.1f mm", func(c *WeatherCondition) *float64 { return &c.Precipitation }},
+	}
+}
+
+// clearSkyConditions 不应伴有降水的晴空类天气
+var clearSkyConditions = map[string]bool{"晴": true, "少云": true, "晴间多云": true}
+
+// Validate 使用默认规则校验全部天气数据，返回包含每条记录、每个字段问题的校验报告
+func Validate(conditions []WeatherCondition) ValidationReport {
+	return ValidateWithRules(conditions, DefaultValidationRules())
+}
+
+// ValidateWithRules 使用指定规则校验全部天气数据，天气现象的合理性按默认配置规范化后的天气状况(如 Sunny 为晴)检查
+func ValidateWithRules(conditions []WeatherCondition, rules ValidationRules) ValidationReport {
+	report := validateFields(conditions, rules, NewAnalyzer(nil).normalizedCondition)
+	records := make([]AnalyzedRecord, 0, len(conditions))
+	invalid := report.invalidSet()
+	for i, c := range conditions {
+		if !invalid[i+1] {
+			records = append(records, AnalyzedRecord{WeatherCondition: c, Index: i + 1})
+		}
+	}
+	tl := newTimeline(records, time.Local)
+	report.addFlags(tl.flags...)
+	report.addFlags(temporalFlags(tl.records, tl.timed, rules)...)
+	return report
+}
+
+// validateFields 校验各记录的数值范围及单条记录的合理性，normalize 为天气状况规范化方法
+func validateFields(conditions []WeatherCondition, rules ValidationRules, normalize func(string) string) ValidationReport {
+	report := ValidationReport{Total: len(conditions)}
+	for i := range conditions {
+		issues := rules.check(conditions[i], i+1)
+		if len(issues) > 0 {
+			report.Issues = append(report.Issues, issues...)
+			report.InvalidRecords = append(report.InvalidRecords, i+1)
+			continue
+		}
+		report.Flags = append(report.Flags, rules.plausibility(conditions[i], normalize(conditions[i].Condition), i+1)...)
+	}
+	return report
+}
+
+// invalidSet 无效记录序号集合
+func (r ValidationReport) invalidSet() map[int]bool {
+	invalid := make(map[int]bool, len(r.InvalidRecords))
+	for _, index := range r.InvalidRecords {
+		invalid[index] = true
+	}
+	return invalid
+}
+
+// addFlags 添加数据质量标记并按记录序号排列
+func (r *ValidationReport) addFlags(flags ...ValidationIssue) {
+	if len(flags) == 0 {
+		return
+	}
+	r.Flags = append(r.Flags, flags...)
+	sort.SliceStable(r.Flags, func(i, j int) bool {
+		return r.Flags[i].Index < r.Flags[j].Index
+	})
+}
+
+// check 检查单条天气数据的全部数值字段
+func (r ValidationRules) check(c WeatherCondition, index int) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, fr := range r.fieldRanges() {
+		v := *fr.value(&c)
+		if !math.IsNaN(v) && v >= fr.min && v <= fr.max {
+			continue
+		}
+		issues = append(issues, ValidationIssue{
+			Index:   index,
+			Field:   fr.field,
+			Value:   v,
+			Code:    fr.code,
+			Rule:    fmt.Sprintf("%s取值范围%g~%g", fr.field, fr.min, fr.max),
+			Message: fmt.Sprintf(fr.format, v),
+		})
+	}
+	if d := c.WindDirection; d != nil && (math.IsNaN(*d) || *d < 0 || *d > 360) {
+		issues = append(issues, ValidationIssue{
+			Index:   index,
+			Field:   "WindDirection",
+			Value:   *d,
+			Code:    utils.ErrInvalidWindDirection,
+			Rule:    "WindDirection取值范围0~360",
+			Message: fmt.Sprintf("风向数据异常: %.1f°", *d),
+		})
+	}
+	return issues
+}
+
+// plausibility 检查单条天气数据的时间、天气现象及跨字段合理性，condition 为规范化后的天气状况
+func (r ValidationRules) plausibility(c WeatherCondition, condition string, index int) []ValidationIssue {
+	var flags []ValidationIssue
+	flag := func(field string, value any, code utils.ErrorCode, rule, message string) {
+		flags = append(flags, ValidationIssue{Index: index, Field: field, Value: value, Code: code, Rule: rule, Message: message})
+	}
+
+	if strings.TrimSpace(c.Time) == "" {
+		flag("Time", c.Time, utils.ErrMissingTime, "观测时间不能为空", "缺少观测时间")
+	}
+	if strings.TrimSpace(c.Condition) == "" {
+		flag("Condition", c.Condition, utils.ErrMissingCondition, "天气状况不能为空", "缺少天气状况")
+	}
+	if c.Precipitation > 0 && clearSkyConditions[condition] {
+		flag("Precipitation", c.Precipitation, utils.ErrImplausibleData, "晴空类天气不应有降水",
+			fmt.Sprintf("天气状况为%s但降水量为%.1f mm", c.Condition, c.Precipitation))
+	}
+	if strings.Contains(condition, "雪") && c.Temperature > r.MaxSnowTemperature {
+		flag("Temperature", c.Temperature, utils.ErrImplausibleData, fmt.Sprintf("降雪类天气温度不高于%g°C", r.MaxSnowTemperature),
+			fmt.Sprintf("天气状况为%s但温度为%.1f°C", c.Condition, c.Temperature))
+	}
+	if c.DewPoint != nil {
+		expected := DewPoint(c.Temperature, c.Humidity)
+		if math.IsNaN(*c.DewPoint) || math.Abs(*c.DewPoint-expected) > r.MaxDewPointDeviation {
+			flag("DewPoint", *c.DewPoint, utils.ErrImplausibleData, fmt.Sprintf("露点与温湿度推算值偏差不超过%g°C", r.MaxDewPointDeviation),
+				fmt.Sprintf("露点%.1f°C与温度%.1f°C、湿度%.0f%%推算的露点%.1f°C不符", *c.DewPoint, c.Temperature, c.Humidity, expected))
+		}
+	}
+	return flags
+}
+
+// clamp 将越界数值截断到有效范围，存在无法截断的数值时返回 false
+func (r ValidationRules) clamp(c *WeatherCondition) bool {
+	for _, fr := range r.fieldRanges() {
+		v := fr.value(c)
+		if math.IsNaN(*v) {
+			return false
+		}
+		*v = math.Max(fr.min, math.Min(fr.max, *v))
+	}
+	if c.WindDirection != nil {
+		if math.IsNaN(*c.WindDirection) {
+			return false
+		}
+		// 风向按圆周换算到0-360度，不修改调用方数据
+		direction := math.Mod(math.Mod(*c.WindDirection, 360)+360, 360)
+		c.WindDirection = &direction
+	}
+	return true
+}
+
+// validateWeatherCondition 使用默认规则验证天气条件数据，返回首个问题
+func validateWeatherCondition(c WeatherCondition) *utils.WeatherError {
+	if issues := DefaultValidationRules().check(c, 0); len(issues) > 0 {
+		return issues[0].err()
+	}
+	return nil
+}
+
+// validationOutcome 无效记录处理结果
+type validationOutcome struct {
+	report  ValidationReport
+	skipped []int
+	clamped []int
+}
+
+// applyInvalidRecordPolicy 按无效记录处理策略处理天气数据
+func applyInvalidRecordPolicy(conditions []WeatherCondition, policy InvalidRecordPolicy, rules ValidationRules, normalize func(string) string) ([]AnalyzedRecord, validationOutcome, error) {
+	outcome := validationOutcome{report: validateFields(conditions, rules, normalize)}
+	if !outcome.report.Valid() && policy == InvalidRecordReject {
+		return nil, outcome, outcome.report.Err()
+	}
+
+	invalid := outcome.report.invalidSet()
+	records := make([]AnalyzedRecord, 0, len(conditions))
+	for i, c := range conditions {
+		if !invalid[i+1] {
+			records = append(records, AnalyzedRecord{WeatherCondition: c, Index: i + 1})
+			continue
+		}
+		if policy == InvalidRecordClamp && rules.clamp(&c) {
+			records = append(records, AnalyzedRecord{WeatherCondition: c, Index: i + 1})
+			outcome.clamped = append(outcome.clamped, i+1)
+			continue
+		}
+		outcome.skipped = append(outcome.skipped, i+1)
+	}
+	if len(records) == 0 {
+		return nil, outcome, &utils.WeatherError{
+			Code:    utils.ErrEmptyData,
+			Message: "没有有效的天气数据",
+			Err:     outcome.report.Err(),
+		}
+	}
+	return records, outcome, nil
+}
@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+func TestValidate(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 60.0, WindSpeed: 5.0},
+		{Time: "2024-01-01 01:00", Temperature: 120.0, Condition: "晴", Humidity: 160.0, WindSpeed: 5.0},
+		{Time: "2024-01-01 02:00", Temperature: 24.0, Condition: "多云", Humidity: 60.0, WindSpeed: math.NaN()},
+	}
+
+	report := Validate(conditions)
+	if report.Valid() {
+		t.Fatal("期望校验不通过")
+	}
+	if len(report.Issues) != 3 {
+		t.Fatalf("校验问题数量错误，期望 3，实际 %d", len(report.Issues))
+	}
+	if len(report.InvalidRecords) != 2 || report.InvalidRecords[0] != 2 || report.InvalidRecords[1] != 3 {
+		t.Errorf("无效记录序号错误，实际 %v", report.InvalidRecords)
+	}
+	if report.Issues[1].Field != "Humidity" || report.Issues[1].Code != utils.ErrInvalidHumidity {
+		t.Errorf("校验问题错误，实际 %+v", report.Issues[1])
+	}
+	if !errors.Is(report.Err(), utils.ErrInvalidTemperature) {
+		t.Errorf("校验错误码错误: %v", report.Err())
+	}
+}
+
+func TestInvalidRecordPolicy(t *testing.T) {
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 20.0, Condition: "晴", Humidity: 60.0, WindSpeed: 2.0},
+		{Time: "2024-01-01 01:00", Temperature: 22.0, Condition: "晴", Humidity: 105.0, WindSpeed: 2.0},
+		{Time: "2024-01-01 02:00", Temperature: math.NaN(), Condition: "多云", Humidity: 60.0, WindSpeed: 2.0},
+	}
+
+	analyzer, err := NewWeatherAnalyzerWithPolicy(conditions, InvalidRecordReject)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	if _, err := analyzer.Analyze(); !errors.Is(err, utils.ErrInvalidHumidity) {
+		t.Errorf("拒绝策略应返回湿度错误，实际 %v", err)
+	}
+
+	analyzer, _ = NewWeatherAnalyzerWithPolicy(conditions, InvalidRecordSkip)
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.SkippedRecords) != 2 || result.AverageTemperature != 20.0 {
+		t.Errorf("跳过策略结果错误，跳过 %v，平均温度 %.1f", result.SkippedRecords, result.AverageTemperature)
+	}
+
+	analyzer, _ = NewWeatherAnalyzerWithPolicy(conditions, InvalidRecordClamp)
+	result, err = analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	if len(result.ClampedRecords) != 1 || result.ClampedRecords[0] != 2 {
+		t.Errorf("截断记录错误，实际 %v", result.ClampedRecords)
+	}
+	if len(result.SkippedRecords) != 1 || result.SkippedRecords[0] != 3 {
+		t.Errorf("无法截断的记录应被跳过，实际 %v", result.SkippedRecords)
+	}
+	if len(result.ValidationIssues) != 2 {
+		t.Errorf("校验问题数量错误，期望 2，实际 %d", len(result.ValidationIssues))
+	}
+}
+
+func TestValidateRulesAndFlags(t *testing.T) {
+	dew := 5.0
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: -60.0, Condition: "晴", Humidity: 40.0},
+		{Time: "2024-01-01 01:00", Temperature: -45.0, Condition: "晴", Humidity: 40.0, Precipitation: 1.2},
+		{Time: "", Temperature: 30.0, Condition: "小雪", Humidity: 100.0, DewPoint: &dew},
+	}
+
+	// 默认规则下数值均有效，仅产生数据质量标记
+	report := Validate(conditions)
+	if !report.Valid() {
+		t.Fatalf("期望校验通过，实际 %+v", report.Issues)
+	}
+	codes := map[utils.ErrorCode]int{}
+	for _, f := range report.Flags {
+		codes[f.Code]++
+	}
+	if codes[utils.ErrImplausibleData] != 3 || codes[utils.ErrTemperatureJump] != 2 || codes[utils.ErrMissingTime] != 1 {
+		t.Errorf("数据质量标记错误，实际 %v", codes)
+	}
+
+	// 热带站点收紧温度范围
+	rules := DefaultValidationRules()
+	rules.MinTemperature = -10
+	report = ValidateWithRules(conditions, rules)
+	if len(report.InvalidRecords) != 2 {
+		t.Errorf("无效记录数量错误，期望 2，实际 %v", report.InvalidRecords)
+	}
+}
+
+func TestPlausibilityNormalizedCondition(t *testing.T) {
+	// 别名、英文及图标代码按规范化后的天气状况检查合理性
+	conditions := []WeatherCondition{
+		{Time: "2024-07-01 00:00", Temperature: 25.0, Condition: "晴天", Humidity: 60.0, Precipitation: 1.0},
+		{Time: "2024-07-01 01:00", Temperature: 25.0, Condition: "Sunny", Humidity: 60.0, Precipitation: 1.0},
+		{Time: "2024-07-01 02:00", Temperature: 25.0, Condition: "100", Humidity: 60.0, Precipitation: 1.0},
+		{Time: "2024-07-01 03:00", Temperature: 30.0, Condition: "Light Snow", Humidity: 60.0},
+		{Time: "2024-07-01 04:00", Temperature: 30.0, Condition: "400", Humidity: 60.0},
+	}
+	report := Validate(conditions)
+	flagged := map[int]bool{}
+	for _, f := range report.Flags {
+		if f.Code == utils.ErrImplausibleData {
+			flagged[f.Index] = true
+		}
+	}
+	if len(flagged) != len(conditions) {
+		t.Errorf("合理性检查应标记全部记录，实际 %v", flagged)
+	}
+
+	// 分析时使用分析器的自定义别名
+	conditions[0].Condition = "大太阳"
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	analyzer.SetConditionAliases(map[string]string{"大太阳": "晴"})
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+	implausible := 0
+	for _, f := range result.QualityFlags {
+		if f.Code == utils.ErrImplausibleData {
+			implausible++
+		}
+	}
+	if implausible != len(conditions) {
+		t.Errorf("分析结果中的合理性标记数量错误，期望 %d，实际 %d", len(conditions), implausible)
+	}
+}
+
+func TestIncompleteValidationRules(t *testing.T) {
+	// 只设置了部分字段的规则会将几乎所有记录判为无效，应返回错误
+	if _, err := NewAnalyzerConfig(WithValidationRules(ValidationRules{MaxTemperature: 60})); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("不完整的校验规则应返回 ErrInvalidConfig，实际 %v", err)
+	} else if !strings.Contains(err.Error(), "validation_rules.MaxHumidity") {
+		t.Errorf("错误信息应指出湿度上限，实际 %v", err)
+	}
+
+	conditions := []WeatherCondition{
+		{Time: "2024-01-01 00:00", Temperature: 25.0, Condition: "晴", Humidity: 50.0},
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	if err := analyzer.SetValidationRules(ValidationRules{}); !errors.Is(err, utils.ErrInvalidConfig) {
+		t.Errorf("零值校验规则应返回 ErrInvalidConfig，实际 %v", err)
+	}
+	rules := DefaultValidationRules()
+	rules.MaxTemperature = 60
+	if err := analyzer.SetValidationRules(rules); err != nil {
+		t.Fatalf("设置校验规则失败: %v", err)
+	}
+	if _, err := analyzer.Analyze(); err != nil {
+		t.Errorf("分析天气状况失败: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/louismax/weather_analyzer/utils"
+)
+
+// beaufortScale 蒲福风级各级风速下限（米/秒），依据 GB/T 28591-2012《风力等级》，第i个元素为 i+1 级
+var beaufortScale = []float64{0.3, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7, 37.0, 41.5, 46.2, 51.0, 56.1}
+
+// BeaufortForce 按风速计算风力等级(0-17级)
+func BeaufortForce(speed float64) int {
+	force := 0
+	for i, min := range beaufortScale {
+		if speed >= min {
+			force = i + 1
+		}
+	}
+	return force
+}
+
+// windDirections 16方位风向名称，从北开始顺时针排列，每个方位22.5度
+var windDirections = []string{
+	"北", "北东北", "东北", "东东北", "东", "东东南", "东南", "南东南",
+	"南", "南西南", "西南", "西西南", "西", "西西北", "西北", "北西北",
+}
+
+// englishWindDirections 16方位风向英文缩写，与 windDirections 一一对应
+var englishWindDirections = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// windDirectionAliases 风向的其他中文写法
+var windDirectionAliases = map[string]float64{
+	"东北偏北": 22.5, "东北偏东": 67.5, "东南偏东": 112.5, "东南偏南": 157.5,
+	"西南偏南": 202.5, "西南偏西": 247.5, "西北偏西": 292.5, "西北偏北": 337.5,
+}
+
+// ParseWindDirection 解析风向，支持角度(如和风天气的 wind360)、中文方位(如 东南风、东南、东南偏南)及英文缩写(如 SE、NNW)
+// 返回0-360度，0为北风，顺时针增加；旋转风、无持续风向等无法确定方向的风向返回错误
+func ParseWindDirection(value string) (float64, error) {
+	text := strings.TrimSpace(value)
+	if degrees, err := strconv.ParseFloat(text, 64); err == nil {
+		if math.IsNaN(degrees) || degrees < 0 || degrees > 360 {
+			return 0, &utils.WeatherError{
+				Code:    utils.ErrInvalidWindDirection,
+				Message: fmt.Sprintf("风向角度超出范围: %s", value),
+				Field:   "WindDirection",
+				Value:   value,
+			}
+		}
+		return math.Mod(degrees, 360), nil
+	}
+
+	name := strings.TrimSuffix(text, "风")
+	for i := range windDirections {
+		if name == windDirections[i] || strings.EqualFold(name, englishWindDirections[i]) {
+			return float64(i) * 22.5, nil
+		}
+	}
+	if degrees, ok := windDirectionAliases[name]; ok {
+		return degrees, nil
+	}
+	return 0, &utils.WeatherError{
+		Code:    utils.ErrInvalidWindDirection,
+		Message: fmt.Sprintf("无法解析风向: %s", value),
+		Field:   "WindDirection",
+		Value:   value,
+	}
+}
+
+// WindDirectionName 风向角度对应的16方位名称，如 东南、南东南
+func WindDirectionName(degrees float64) string {
+	return windDirections[sectorOf(degrees, len(windDirections))]
+}
+
+// sectorOf 风向角度所在的扇区序号，扇区以正北为中心依次顺时针划分
+func sectorOf(degrees float64, sectors int) int {
+	width := 360 / float64(sectors)
+	sector := int(math.Floor(math.Mod(degrees+width/2, 360) / width))
+	return sector % sectors
+}
+
+// octantNames 8方位风向名称，用于描述盛行风向
+var octantNames = []string{"北", "东北", "东", "东南", "南", "西南", "西", "西北"}
+
+// WindRoseSpeedBins 风玫瑰图默认风速分档下限（米/秒）：1-2级、3-4级、5-6级、7级及以上
+var WindRoseSpeedBins = []float64{0.3, 3.4, 8.0, 13.9}
+
+// WindRose 风玫瑰图，按16方位及风速分档统计各风向的出现频率
+type WindRose struct {
+	// SpeedBins 风速分档下限（米/秒）
+	SpeedBins []float64
+	// Sectors 16个方位，从北开始顺时针排列
+	Sectors []WindRoseSector
+	// Calm 静风(风速低于首个分档下限)频率（%）
+	Calm float64
+}
+
+// WindRoseSector 风玫瑰图的单个方位
+type WindRoseSector struct {
+	// Direction 方位名称，如 东南
+	Direction string
+	// Degrees 方位中心角度
+	Degrees float64
+	// Frequencies 各风速分档的出现频率（%），按记录代表的时长计算
+	Frequencies []float64
+}
+
+// WindGust 阵风
+type WindGust struct {
+	// Index 记录序号，从1开始
+	Index int
+	// Time 观测时间，无观测时间时为零值
+	Time time.Time
+	// Speed 风速（米/秒）
+	Speed float64
+	// Force 风力等级
+	Force int
+	// Excess 超出相邻记录平均风速的幅度（米/秒）
+	Excess float64
+}
+
+// windStats 风向风力统计
+type windStats struct {
+	prevailing         string
+	minForce, maxForce int
+	rose               *WindRose
+	gusts              []WindGust
+}
+
+// analyzeWind 统计风力等级、盛行风向、风玫瑰图及阵风
+// 风速超出前后记录平均风速 gustThreshold 及以上的记录视为阵风，不参与风力范围统计
+func analyzeWind(records []AnalyzedRecord, gustThreshold float64) windStats {
+	var stats windStats
+	gusty := make(map[int]bool)
+	for i, r := range records {
+		var neighbours []float64
+		if i > 0 {
+			neighbours = append(neighbours, records[i-1].WindSpeed)
+		}
+		if i+1 < len(records) {
+			neighbours = append(neighbours, records[i+1].WindSpeed)
+		}
+		if len(neighbours) == 0 || gustThreshold <= 0 {
+			continue
+		}
+		var sum float64
+		for _, s := range neighbours {
+			sum += s
+		}
+		if excess := r.WindSpeed - sum/float64(len(neighbours)); excess >= gustThreshold {
+			gusty[i] = true
+			stats.gusts = append(stats.gusts, WindGust{Index: r.Index, Time: r.At, Speed: r.WindSpeed, Force: r.WindForce, Excess: excess})
+		}
+	}
+
+	// 风力范围，不含静风及阵风
+	stats.minForce = -1
+	for i, r := range records {
+		if gusty[i] || r.WindForce == 0 {
+			continue
+		}
+		if stats.minForce < 0 || r.WindForce < stats.minForce {
+			stats.minForce = r.WindForce
+		}
+		stats.maxForce = max(stats.maxForce, r.WindForce)
+	}
+	if stats.minForce < 0 {
+		stats.minForce = 0
+	}
+
+	// 风玫瑰图及盛行风向，按记录代表的时长计算频率
+	rose := &WindRose{SpeedBins: WindRoseSpeedBins}
+	for i, name := range windDirections {
+		rose.Sectors = append(rose.Sectors, WindRoseSector{
+			Direction:   name,
+			Degrees:     float64(i) * 22.5,
+			Frequencies: make([]float64, len(WindRoseSpeedBins)),
+		})
+	}
+	var total, calm float64
+	octants := make([]float64, len(octantNames))
+	for _, r := range records {
+		if r.WindDirection == nil {
+			continue
+		}
+		hours := r.Duration.Hours()
+		total += hours
+		bin := -1
+		for b, min := range WindRoseSpeedBins {
+			if r.WindSpeed >= min {
+				bin = b
+			}
+		}
+		if bin < 0 {
+			calm += hours
+			continue
+		}
+		rose.Sectors[sectorOf(*r.WindDirection, len(windDirections))].Frequencies[bin] += hours
+		octants[sectorOf(*r.WindDirection, len(octantNames))] += hours
+	}
+	if total == 0 {
+		return stats
+	}
+	for _, sector := range rose.Sectors {
+		for b := range sector.Frequencies {
+			sector.Frequencies[b] = sector.Frequencies[b] / total * 100
+		}
+	}
+	rose.Calm = calm / total * 100
+	stats.rose = rose
+
+	var prevailingHours float64
+	for i, hours := range octants {
+		if hours > prevailingHours {
+			prevailingHours = hours
+			stats.prevailing = octantNames[i]
+		}
+	}
+	return stats
+}
+
+// phrase 风向风力描述，如“东南风3-4级”，没有风向数据时为“风力3-4级”，全部为静风时为空
+func (s windStats) phrase() string {
+	if s.maxForce == 0 {
+		return ""
+	}
+	force := fmt.Sprintf("%d级", s.maxForce)
+	if s.minForce < s.maxForce {
+		force = fmt.Sprintf("%d-%d级", s.minForce, s.maxForce)
+	}
+	if s.prevailing == "" {
+		return "风力" + force
+	}
+	return s.prevailing + "风" + force
+}
+
+// meanWindDirection 按矢量平均计算多个风向的平均方向，没有风向时返回 nil
+func meanWindDirection(directions []float64) *float64 {
+	if len(directions) == 0 {
+		return nil
+	}
+	var x, y float64
+	for _, d := range directions {
+		rad := d * math.Pi / 180
+		x += math.Sin(rad)
+		y += math.Cos(rad)
+	}
+	mean := math.Mod(math.Atan2(x, y)*180/math.Pi+360, 360)
+	return &mean
+}
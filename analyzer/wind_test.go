@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseWindDirection(t *testing.T) {
+	cases := map[string]float64{
+		"135":    135,
+		"360":    0,
+		"东南风":    135,
+		"东南":     135,
+		"东南偏南":   157.5,
+		"SSE":    157.5,
+		"nw":     315,
+		" 北西北风 ": 337.5,
+	}
+	for value, expected := range cases {
+		degrees, err := ParseWindDirection(value)
+		if err != nil || degrees != expected {
+			t.Errorf("解析风向 %q 错误，期望 %.1f，实际 %.1f (%v)", value, expected, degrees, err)
+		}
+	}
+	for _, value := range []string{"旋转风", "无持续风向", "400"} {
+		if _, err := ParseWindDirection(value); err == nil {
+			t.Errorf("期望风向 %q 返回错误", value)
+		}
+	}
+	if name := WindDirectionName(350); name != "北" {
+		t.Errorf("风向名称错误，期望 北，实际 %s", name)
+	}
+}
+
+func TestBeaufortForce(t *testing.T) {
+	cases := map[float64]int{0: 0, 0.2: 0, 1.5: 1, 3.4: 3, 5.4: 3, 5.5: 4, 17.2: 8, 60: 17}
+	for speed, expected := range cases {
+		if force := BeaufortForce(speed); force != expected {
+			t.Errorf("风速 %.1f 风力等级错误，期望 %d，实际 %d", speed, expected, force)
+		}
+	}
+}
+
+func TestWindStatistics(t *testing.T) {
+	speeds := []float64{4.0, 5.0, 6.0, 15.0, 5.0, 4.5}
+	directions := []float64{130, 140, 135, 180, 120, 300}
+	var conditions []WeatherCondition
+	for i := range speeds {
+		conditions = append(conditions, WeatherCondition{
+			Time:          fmt.Sprintf("2024-07-01T%02d:00:00+08:00", i),
+			Temperature:   26.0,
+			Condition:     "多云",
+			Humidity:      60.0,
+			WindSpeed:     speeds[i],
+			WindDirection: &directions[i],
+		})
+	}
+	analyzer, err := NewWeatherAnalyzer(conditions)
+	if err != nil {
+		t.Fatalf("创建天气分析器失败: %v", err)
+	}
+	result, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("分析天气状况失败: %v", err)
+	}
+
+	if result.PrevailingWindDirection != "东南" {
+		t.Errorf("盛行风向错误，期望 东南，实际 %s", result.PrevailingWindDirection)
+	}
+	if len(result.Gusts) != 1 || result.Gusts[0].Index != 4 || result.Gusts[0].Force != 7 {
+		t.Fatalf("阵风检测错误: %+v", result.Gusts)
+	}
+	// 阵风不参与风力范围统计
+	if result.MinWindForce != 3 || result.MaxWindForce != 4 {
+		t.Errorf("风力范围错误，期望 3-4级，实际 %d-%d级", result.MinWindForce, result.MaxWindForce)
+	}
+	if !strings.Contains(result.Description, "东南风3-4级，阵风7级") {
+		t.Errorf("天气描述错误，实际 %s", result.Description)
+	}
+
+	rose := result.WindRose
+	if rose == nil || len(rose.Sectors) != 16 {
+		t.Fatal("风玫瑰图错误")
+	}
+	var total float64
+	for _, sector := range rose.Sectors {
+		for _, f := range sector.Frequencies {
+			total += f
+		}
+	}
+	if total+rose.Calm < 99.99 || total+rose.Calm > 100.01 {
+		t.Errorf("风玫瑰图频率合计错误，实际 %.2f", total+rose.Calm)
+	}
+	// 东南方位3-4级风出现3小时
+	if f := rose.Sectors[6].Frequencies[1]; f < 49.9 || f > 50.1 {
+		t.Errorf("东南方位3-4级频率错误，期望 50，实际 %.2f", f)
+	}
+}
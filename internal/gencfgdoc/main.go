@@ -0,0 +1,88 @@
+// gencfgdoc 根据内置默认配置(analyzer/defaults.json)及区域气候配置(analyzer/profiles)生成 DefaultCfg.md
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/louismax/weather_analyzer/analyzer"
+)
+
+func main() {
+	out := flag.String("o", "DefaultCfg.md", "输出文件")
+	flag.Parse()
+
+	f := analyzer.DefaultConfigFile()
+	var buf bytes.Buffer
+	buf.WriteString("<!-- 本文件由 internal/gencfgdoc 根据 analyzer/defaults.json 生成，请勿手动修改 -->\n\n")
+	buf.WriteString("默认配置可通过配置文件覆盖，配置文件格式见 README 的“配置文件”一节。\n\n")
+	writeTable(&buf, "默认天气状况权重", "天气状况", "权重", f.ConditionWeights, f.Notes["condition_weights"])
+	buf.WriteString("权重设置原则：极端天气(如暴雨、强雷阵雨等)权重最高，伴有冰雹、雷电等特殊现象的天气权重较高，降水强度大、持续时间长的天气权重较高。\n\n")
+	writeTable(&buf, "默认降水量阈值", "天气状况", "降水量（毫米）", f.PrecipitationThresholds, f.Notes["precipitation_thresholds"])
+	writeTable(&buf, "默认风速阈值", "天气状况", "风速（米/秒）", f.WindSpeedThresholds, f.Notes["wind_speed_thresholds"])
+
+	buf.WriteString("## 其他默认配置\n")
+	buf.WriteString("| 配置项 | 默认值 | 说明 |\n|---|---|---|\n")
+	boostNotes := f.Notes["boost_factors"]
+	fmt.Fprintf(&buf, "| boost_factors.precipitation | %g | %s |\n", *f.BoostFactors.Precipitation, boostNotes["precipitation"])
+	fmt.Fprintf(&buf, "| boost_factors.wind_speed | %g | %s |\n", *f.BoostFactors.WindSpeed, boostNotes["wind_speed"])
+	fmt.Fprintf(&buf, "| other_condition_min_share | %g | 其他重要天气状况的最低得分占比（%%） |\n", *f.OtherConditionMinShare)
+	fmt.Fprintf(&buf, "| max_other_conditions | %d | 其他重要天气状况的最大数量，0表示不限 |\n", *f.MaxOtherConditions)
+
+	buf.WriteString("\n## 区域气候配置\n")
+	buf.WriteString("区域气候配置在默认配置基础上调整以下各项，未列出的项与默认配置相同\n")
+	for _, name := range analyzer.Profiles() {
+		p, _ := analyzer.LookupProfile(name)
+		fmt.Fprintf(&buf, "\n### %s\n%s\n\n", name, p.Description)
+		buf.WriteString("| 配置项 | 值 |\n|---|---|\n")
+		writeOverrides(&buf, "condition_weights", p.ConditionWeights)
+		writeOverrides(&buf, "precipitation_thresholds", p.PrecipitationThresholds)
+		writeOverrides(&buf, "wind_speed_thresholds", p.WindSpeedThresholds)
+		if p.BoostFactors != nil && p.BoostFactors.Precipitation != nil {
+			fmt.Fprintf(&buf, "| boost_factors.precipitation | %g |\n", *p.BoostFactors.Precipitation)
+		}
+		if p.BoostFactors != nil && p.BoostFactors.WindSpeed != nil {
+			fmt.Fprintf(&buf, "| boost_factors.wind_speed | %g |\n", *p.BoostFactors.WindSpeed)
+		}
+	}
+
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeOverrides 按名称输出区域气候配置调整的项
+func writeOverrides(buf *bytes.Buffer, section string, values map[string]float64) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "| %s.%s | %g |\n", section, k, values[k])
+	}
+}
+
+// writeTable 按数值由高到低输出一节配置
+func writeTable(buf *bytes.Buffer, title, keyHeader, valueHeader string, values map[string]float64, notes map[string]string) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if values[keys[i]] != values[keys[j]] {
+			return values[keys[i]] > values[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	fmt.Fprintf(buf, "## %s\n", title)
+	fmt.Fprintf(buf, "| %s | %s | 说明 |\n|---|---|---|\n", keyHeader, valueHeader)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "| %s | %g | %s |\n", k, values[k], notes[k])
+	}
+	buf.WriteString("\n")
+}
@@ -0,0 +1,84 @@
+package qweather
+
+import (
+	"sort"
+	"strings"
+)
+
+// iconTexts 和风天气图标代码对应的天气状况，夜间图标与日间图标对应相同天气状况
+var iconTexts = map[string]string{
+	"100": "晴", "101": "多云", "102": "少云", "103": "晴间多云", "104": "阴",
+	"150": "晴", "151": "多云", "152": "少云", "153": "晴间多云",
+	"300": "阵雨", "301": "强阵雨", "302": "雷阵雨", "303": "强雷阵雨", "304": "雷阵雨伴有冰雹",
+	"305": "小雨", "306": "中雨", "307": "大雨", "308": "极端降雨", "309": "毛毛雨/细雨",
+	"310": "暴雨", "311": "大暴雨", "312": "特大暴雨", "313": "冻雨",
+	"314": "小到中雨", "315": "中到大雨", "316": "大到暴雨", "317": "暴雨到大暴雨", "318": "大暴雨到特大暴雨",
+	"350": "阵雨", "351": "强阵雨", "399": "雨",
+	"400": "小雪", "401": "中雪", "402": "大雪", "403": "暴雪", "404": "雨夹雪",
+	"405": "雨雪天气", "406": "阵雨夹雪", "407": "阵雪", "408": "小到中雪", "409": "中到大雪",
+	"410": "大到暴雪", "456": "阵雨夹雪", "457": "阵雪", "499": "雪",
+	"500": "薄雾", "501": "雾", "502": "霾", "503": "扬沙", "504": "浮尘",
+	"507": "沙尘暴", "508": "强沙尘暴", "509": "浓雾", "510": "强浓雾",
+	"511": "中度霾", "512": "重度霾", "513": "严重霾", "514": "大雾", "515": "特强浓雾",
+	"900": "热", "901": "冷", "999": "未知",
+}
+
+// englishTexts 和风天气英文天气状况(lang=en)对应的中文天气状况，键为小写
+var englishTexts = map[string]string{
+	"sunny": "晴", "clear": "晴", "cloudy": "多云", "few clouds": "少云", "partly cloudy": "晴间多云", "overcast": "阴",
+	"shower rain": "阵雨", "heavy shower rain": "强阵雨", "thundershower": "雷阵雨", "heavy thunderstorm": "强雷阵雨",
+	"thundershower with hail": "雷阵雨伴有冰雹", "light rain": "小雨", "moderate rain": "中雨", "heavy rain": "大雨",
+	"extreme rain": "极端降雨", "drizzle rain": "毛毛雨/细雨", "storm": "暴雨", "heavy storm": "大暴雨",
+	"severe storm": "特大暴雨", "freezing rain": "冻雨", "light to moderate rain": "小到中雨",
+	"moderate to heavy rain": "中到大雨", "heavy rain to storm": "大到暴雨", "storm to heavy storm": "暴雨到大暴雨",
+	"heavy to severe storm": "大暴雨到特大暴雨", "rain": "雨",
+	"light snow": "小雪", "moderate snow": "中雪", "heavy snow": "大雪", "snowstorm": "暴雪", "sleet": "雨夹雪",
+	"rain and snow": "雨雪天气", "shower snow": "阵雨夹雪", "snow flurry": "阵雪", "light to moderate snow": "小到中雪",
+	"moderate to heavy snow": "中到大雪", "heavy snow to snowstorm": "大到暴雪", "snow": "雪",
+	"mist": "薄雾", "foggy": "雾", "haze": "霾", "sand": "扬沙", "dust": "浮尘", "duststorm": "沙尘暴",
+	"sandstorm": "强沙尘暴", "dense fog": "浓雾", "strong fog": "强浓雾", "moderate haze": "中度霾",
+	"heavy haze": "重度霾", "severe haze": "严重霾", "heavy fog": "大雾", "extra heavy fog": "特强浓雾",
+	"hot": "热", "cold": "冷", "unknown": "未知",
+}
+
+// iconCodeOverrides 同一天气状况有多个图标代码时 GetWeatherIconCode 沿用的代码，与早期版本保持一致
+var iconCodeOverrides = map[string]string{
+	"阵雨": "350", "强阵雨": "351", "阵雨夹雪": "456", "阵雪": "457",
+}
+
+// weatherIconCodes 由 iconTexts 生成中文天气状况到图标代码的映射
+// 同一天气状况有多个图标代码时取 iconCodeOverrides 中的代码，其余取代码最小者
+func weatherIconCodes() map[string]string {
+	codes := make(map[string]string, len(iconTexts))
+	for code, text := range iconTexts {
+		if existing, ok := codes[text]; !ok || code < existing {
+			codes[text] = code
+		}
+	}
+	for text, code := range iconCodeOverrides {
+		codes[text] = code
+	}
+	return codes
+}
+
+// WeatherTextByIconCode 获取和风天气图标代码(如 305)对应的中文天气状况
+func WeatherTextByIconCode(code string) (string, bool) {
+	text, ok := iconTexts[strings.TrimSpace(code)]
+	return text, ok
+}
+
+// WeatherTextByEnglish 获取和风天气英文天气状况(如 Light Rain)对应的中文天气状况，忽略大小写及多余空白
+func WeatherTextByEnglish(text string) (string, bool) {
+	zh, ok := englishTexts[strings.ToLower(strings.Join(strings.Fields(text), " "))]
+	return zh, ok
+}
+
+// EnglishWeatherTexts 和风天气全部英文天气状况(小写，按字母排序)，可用于模糊匹配
+func EnglishWeatherTexts() []string {
+	texts := make([]string, 0, len(englishTexts))
+	for text := range englishTexts {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+	return texts
+}
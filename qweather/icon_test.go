@@ -0,0 +1,27 @@
+package qweather
+
+import "testing"
+
+func TestWeatherText(t *testing.T) {
+	if text, ok := WeatherTextByIconCode("309"); !ok || text != "毛毛雨/细雨" {
+		t.Errorf("图标代码对应天气状况错误，实际 %s", text)
+	}
+	if _, ok := WeatherTextByIconCode("000"); ok {
+		t.Error("期望不存在的图标代码返回 false")
+	}
+	if text, ok := WeatherTextByEnglish(" Thundershower  with Hail "); !ok || text != "雷阵雨伴有冰雹" {
+		t.Errorf("英文天气状况对应天气状况错误，实际 %s", text)
+	}
+
+	codes := (&ApiClient{}).GetWeatherIconCode()
+	for text, code := range map[string]string{"晴": "100", "阵雨": "350", "强阵雨": "351", "阵雨夹雪": "456", "阵雪": "457", "未知": "999"} {
+		if codes[text] != code {
+			t.Errorf("%s的图标代码错误，期望 %s，实际 %s", text, code, codes[text])
+		}
+	}
+	for text, code := range codes {
+		if back, _ := WeatherTextByIconCode(code); back != text {
+			t.Errorf("图标代码 %s 与天气状况 %s 不一致", code, text)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package qweather
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Invoker 执行一次HTTP请求
+type Invoker func(req *http.Request) (*http.Response, error)
+
+// Interceptor 请求拦截器，可用于附加日志、指标、链路追踪等逻辑
+// endpoint 为请求的接口路径(如 /v7/historical/weather)，拦截器需调用 next 继续执行请求链
+type Interceptor func(endpoint string, req *http.Request, next Invoker) (*http.Response, error)
+
+// Use 注册请求拦截器，按注册顺序由外向内执行，可与请求并发调用，已开始的请求不受影响
+func (c *ApiClient) Use(interceptors ...Interceptor) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+	// 写时复制，正在执行的请求持有的拦截器链不变
+	next := make([]Interceptor, len(c.interceptors), len(c.interceptors)+len(interceptors))
+	copy(next, c.interceptors)
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			next = append(next, interceptor)
+		}
+	}
+	c.interceptors = next
+}
+
+// invoke 通过拦截器链发送请求
+func (c *ApiClient) invoke(endpoint string, req *http.Request, final Invoker) (*http.Response, error) {
+	c.interceptorsMu.RLock()
+	interceptors := c.interceptors
+	c.interceptorsMu.RUnlock()
+
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(r *http.Request) (*http.Response, error) {
+			return interceptor(endpoint, r, inner)
+		}
+	}
+	return next(req)
+}
+
+// RetryInterceptor 创建重试拦截器，请求发送失败、状态码为429或5xx时最多重试 maxRetries 次
+// 第n次重试前等待 n×backoff，每次重试调用 m.IncRetry 上报，m 为 nil 时不上报
+func RetryInterceptor(m Metrics, maxRetries int, backoff time.Duration) Interceptor {
+	return func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+			if !retryable || attempt > maxRetries {
+				return resp, err
+			}
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			if m != nil {
+				m.IncRetry(endpoint)
+			}
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(time.Duration(attempt) * backoff):
+			}
+		}
+	}
+}
+
+// cachedResponse 缓存的响应
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	expires    time.Time
+}
+
+// responseCache 带过期时间的响应缓存，写入时每隔 ttl 清理一次全部过期条目
+type responseCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]cachedResponse
+	nextSweep time.Time
+}
+
+// get 读取未过期的缓存，已过期的条目同时删除
+func (c *responseCache) get(key string, now time.Time) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[key]
+	if ok && now.After(cached.expires) {
+		delete(c.entries, key)
+		return cachedResponse{}, false
+	}
+	return cached, ok
+}
+
+// put 写入缓存，距上次清理已超过 ttl 时先删除全部过期条目，缓存只保留最近约两个 ttl 内写入的结果
+func (c *responseCache) put(key string, cached cachedResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !now.Before(c.nextSweep) {
+		for k, v := range c.entries {
+			if now.After(v.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	cached.expires = now.Add(c.ttl)
+	c.entries[key] = cached
+}
+
+// CacheInterceptor 创建缓存拦截器，缓存状态码为200的GET请求结果 ttl 时长
+// 缓存按请求地址(查询参数排序后)区分，每次命中调用 m.IncCacheHit 上报，m 为 nil 时不上报
+// 过期条目在写入缓存时定期清理，不会随查询种类无限增长
+func CacheInterceptor(m Metrics, ttl time.Duration) Interceptor {
+	cache := &responseCache{ttl: ttl, entries: make(map[string]cachedResponse)}
+	return func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+		if req.Method != http.MethodGet {
+			return next(req)
+		}
+		key := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path + "?" + req.URL.Query().Encode()
+		if cached, ok := cache.get(key, time.Now()); ok {
+			if m != nil {
+				m.IncCacheHit(endpoint)
+			}
+			return &http.Response{
+				Status:        http.StatusText(cached.statusCode),
+				StatusCode:    cached.statusCode,
+				Header:        cached.header.Clone(),
+				Body:          io.NopCloser(bytes.NewReader(cached.body)),
+				ContentLength: int64(len(cached.body)),
+				Request:       req,
+			}, nil
+		}
+
+		resp, err := next(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		cache.put(key, cachedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body}, time.Now())
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+}
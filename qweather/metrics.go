@@ -0,0 +1,200 @@
+package qweather
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics 请求指标收集接口
+type Metrics interface {
+	// ObserveLatency 记录接口请求耗时
+	ObserveLatency(endpoint string, latency time.Duration)
+	// IncStatus 记录接口响应状态码，请求发送失败时状态码为0
+	IncStatus(endpoint string, statusCode int)
+	// IncCacheHit 记录缓存命中次数
+	IncCacheHit(endpoint string)
+	// IncRetry 记录重试次数
+	IncRetry(endpoint string)
+}
+
+// MetricsInterceptor 创建记录请求耗时与状态码的拦截器
+// 缓存命中与重试次数由 CacheInterceptor、RetryInterceptor 上报，自行实现缓存、重试逻辑的拦截器需自行调用 IncCacheHit、IncRetry
+// m 为 nil 时不记录任何指标
+func MetricsInterceptor(m Metrics) Interceptor {
+	if m == nil {
+		return func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+			return next(req)
+		}
+	}
+	return func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req)
+		m.ObserveLatency(endpoint, time.Since(start))
+		if err != nil || resp == nil {
+			m.IncStatus(endpoint, 0)
+		} else {
+			m.IncStatus(endpoint, resp.StatusCode)
+		}
+		return resp, err
+	}
+}
+
+// DefaultLatencyBuckets 默认请求耗时直方图分桶(秒)
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics 内存指标收集器，可按Prometheus文本格式导出，nil 收集器不记录任何指标
+type PrometheusMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	latencies map[string]*latencyHistogram
+	statuses  map[string]map[int]uint64
+	cacheHits map[string]uint64
+	retries   map[string]uint64
+}
+
+type latencyHistogram struct {
+	counts []uint64 // 各分桶计数(非累计)
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics 创建内存指标收集器，buckets 为空时使用 DefaultLatencyBuckets
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{
+		buckets:   sorted,
+		latencies: make(map[string]*latencyHistogram),
+		statuses:  make(map[string]map[int]uint64),
+		cacheHits: make(map[string]uint64),
+		retries:   make(map[string]uint64),
+	}
+}
+
+// ObserveLatency 记录接口请求耗时
+func (m *PrometheusMetrics) ObserveLatency(endpoint string, latency time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.latencies[endpoint]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[endpoint] = h
+	}
+	seconds := latency.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// IncStatus 记录接口响应状态码
+func (m *PrometheusMetrics) IncStatus(endpoint string, statusCode int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.statuses[endpoint] == nil {
+		m.statuses[endpoint] = make(map[int]uint64)
+	}
+	m.statuses[endpoint][statusCode]++
+}
+
+// IncCacheHit 记录缓存命中次数
+func (m *PrometheusMetrics) IncCacheHit(endpoint string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheHits[endpoint]++
+}
+
+// IncRetry 记录重试次数
+func (m *PrometheusMetrics) IncRetry(endpoint string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[endpoint]++
+}
+
+// WritePrometheus 按Prometheus文本格式导出全部指标
+func (m *PrometheusMetrics) WritePrometheus(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP qweather_request_duration_seconds QWeather API request latency in seconds.\n")
+	b.WriteString("# TYPE qweather_request_duration_seconds histogram\n")
+	for _, endpoint := range sortedKeys(m.latencies) {
+		h := m.latencies[endpoint]
+		label := fmt.Sprintf("endpoint=%q", endpoint)
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "qweather_request_duration_seconds_bucket{%s,le=%q} %d\n", label, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "qweather_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(&b, "qweather_request_duration_seconds_sum{%s} %s\n", label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "qweather_request_duration_seconds_count{%s} %d\n", label, h.count)
+	}
+
+	b.WriteString("# HELP qweather_requests_total QWeather API requests by endpoint and status code.\n")
+	b.WriteString("# TYPE qweather_requests_total counter\n")
+	for _, endpoint := range sortedKeys(m.statuses) {
+		codes := make([]int, 0, len(m.statuses[endpoint]))
+		for code := range m.statuses[endpoint] {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(&b, "qweather_requests_total{endpoint=%q,code=\"%d\"} %d\n", endpoint, code, m.statuses[endpoint][code])
+		}
+	}
+
+	b.WriteString("# HELP qweather_cache_hits_total QWeather API cache hits.\n")
+	b.WriteString("# TYPE qweather_cache_hits_total counter\n")
+	for _, endpoint := range sortedKeys(m.cacheHits) {
+		fmt.Fprintf(&b, "qweather_cache_hits_total{endpoint=%q} %d\n", endpoint, m.cacheHits[endpoint])
+	}
+
+	b.WriteString("# HELP qweather_retries_total QWeather API request retries.\n")
+	b.WriteString("# TYPE qweather_retries_total counter\n")
+	for _, endpoint := range sortedKeys(m.retries) {
+		fmt.Fprintf(&b, "qweather_retries_total{endpoint=%q} %d\n", endpoint, m.retries[endpoint])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
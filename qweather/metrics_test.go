@@ -0,0 +1,196 @@
+package qweather
+
+import (
+	"crypto/ed25519"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApiClientInterceptorMetrics(t *testing.T) {
+	_, pk, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewQWeatherApiClientByPKED("YOUR_KEY_ID", "YOUR_PROJECT_ID", "YOUR_API_HOST", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := NewPrometheusMetrics()
+	var order []string
+	client.Use(
+		MetricsInterceptor(metrics),
+		func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+			order = append(order, "trace")
+			if req.Header.Get("Authorization") != client.Token {
+				t.Errorf("请求未携带鉴权信息")
+			}
+			return next(req)
+		},
+		// 模拟缓存，直接返回结果而不发送请求
+		func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+			order = append(order, "cache")
+			metrics.IncCacheHit(endpoint)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"code":"200"}`)),
+			}, nil
+		},
+	)
+
+	resp, err := client.Request(APIGeoCityLookup, map[string]string{"location": "岳麓"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != `{"code":"200"}` {
+		t.Errorf("响应内容错误，实际 %s", resp.Body)
+	}
+	if strings.Join(order, ",") != "trace,cache" {
+		t.Errorf("拦截器执行顺序错误，实际 %v", order)
+	}
+
+	var out strings.Builder
+	if err := metrics.WritePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`qweather_request_duration_seconds_count{endpoint="/geo/v2/city/lookup"} 1`,
+		`qweather_requests_total{endpoint="/geo/v2/city/lookup",code="200"} 1`,
+		`qweather_cache_hits_total{endpoint="/geo/v2/city/lookup"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("指标输出缺少 %s\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCacheAndRetryInterceptors(t *testing.T) {
+	_, pk, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewQWeatherApiClientByPKED("YOUR_KEY_ID", "YOUR_PROJECT_ID", "YOUR_API_HOST", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := NewPrometheusMetrics()
+	calls := 0
+	client.Use(
+		MetricsInterceptor(metrics),
+		CacheInterceptor(metrics, time.Minute),
+		RetryInterceptor(metrics, 2, 0),
+		// 模拟服务端，第一次请求返回503
+		func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+			calls++
+			status := http.StatusOK
+			if calls == 1 {
+				status = http.StatusServiceUnavailable
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(`{"code":"200"}`)),
+			}, nil
+		},
+	)
+
+	params := map[string]string{"location": "岳麓", "lang": "zh"}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Request(APIGeoCityLookup, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp.Body) != `{"code":"200"}` {
+			t.Errorf("第%d次响应内容错误，实际 %s", i+1, resp.Body)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("服务端调用次数错误，期望 2(重试1次、缓存命中1次)，实际 %d", calls)
+	}
+
+	var out strings.Builder
+	if err := metrics.WritePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`qweather_cache_hits_total{endpoint="/geo/v2/city/lookup"} 1`,
+		`qweather_retries_total{endpoint="/geo/v2/city/lookup"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("指标输出缺少 %s\n%s", want, out.String())
+		}
+	}
+}
+
+func TestUseConcurrentWithRequest(t *testing.T) {
+	_, pk, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewQWeatherApiClientByPKED("YOUR_KEY_ID", "YOUR_PROJECT_ID", "YOUR_API_HOST", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respond := func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	}
+	client.Use(respond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.Use(func(endpoint string, req *http.Request, next Invoker) (*http.Response, error) {
+				return next(req)
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := client.invoke(APIGeoCityLookup, httptest.NewRequest(http.MethodGet, "/", nil), nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResponseCacheSweep(t *testing.T) {
+	cache := &responseCache{ttl: time.Minute, entries: make(map[string]cachedResponse)}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		cache.put(strings.Repeat("k", i+1), cachedResponse{statusCode: http.StatusOK}, start)
+	}
+	if _, ok := cache.get("k", start.Add(30*time.Second)); !ok {
+		t.Error("未过期的缓存应命中")
+	}
+	// 全部条目过期后写入新条目，过期条目应被清理
+	cache.put("new", cachedResponse{statusCode: http.StatusOK}, start.Add(2*time.Minute))
+	if len(cache.entries) != 1 {
+		t.Errorf("过期缓存未清理，剩余 %d 条", len(cache.entries))
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	respond := func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	}
+	var pm *PrometheusMetrics
+	for _, m := range []Metrics{nil, pm} {
+		for _, interceptor := range []Interceptor{MetricsInterceptor(m), CacheInterceptor(m, time.Minute), RetryInterceptor(m, 1, 0)} {
+			for i := 0; i < 2; i++ {
+				if _, err := interceptor(APIGeoCityLookup, httptest.NewRequest(http.MethodGet, "/", nil), respond); err != nil {
+					t.Error(err)
+				}
+			}
+		}
+	}
+	if err := pm.WritePrometheus(io.Discard); err != nil {
+		t.Error(err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -27,6 +28,10 @@ type ApiClient struct {
 	PrivateKey ed25519.PrivateKey
 	ApiHost    string
 	Token      string
+
+	interceptorsMu sync.RWMutex
+	interceptors   []Interceptor
+	logger         utils.Logger
 }
 
 // NewQWeatherApiClient 创建一个新的和风天气ApiClient实例
@@ -36,7 +41,8 @@ func NewQWeatherApiClient(kId, subId, apiHost, PrivateKeyPath string) (*ApiClien
 	if err != nil {
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrReadFile,
-			Message: fmt.Sprintf("读取私钥文件失败.%s", err.Error()),
+			Message: "读取私钥文件失败",
+			Err:     err,
 		}
 	}
 	//解析私钥
@@ -52,7 +58,8 @@ func NewQWeatherApiClient(kId, subId, apiHost, PrivateKeyPath string) (*ApiClien
 	if err != nil {
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrInvalidInput,
-			Message: fmt.Sprintf("PKCS#8解析失败.%s", err.Error()),
+			Message: "PKCS#8解析失败",
+			Err:     err,
 		}
 	}
 	ed25519Key, ok := privateKey.(ed25519.PrivateKey)
@@ -80,7 +87,8 @@ func NewQWeatherApiClientByPKString(kId, subId, apiHost, PrivateKey string) (*Ap
 	if err != nil {
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrInvalidInput,
-			Message: fmt.Sprintf("PKCS#8解析失败.%s", err.Error()),
+			Message: "PKCS#8解析失败",
+			Err:     err,
 		}
 	}
 	ed25519Key, ok := privateKey.(ed25519.PrivateKey)
@@ -141,19 +149,21 @@ func (c *ApiClient) Request(methodPath string, params map[string]string) (*Resul
 	client := &http.Client{}
 	request, err := http.NewRequest(http.MethodGet, _url, nil)
 	if err != nil {
-		utils.PrintErrorLog("请求创建失败,error:%+v", err)
+		c.log().Error("请求创建失败", "endpoint", methodPath, "error", err)
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrRequestFailed,
-			Message: fmt.Sprintf("请求创建失败,error:%+v", err),
+			Message: "请求创建失败",
+			Err:     err,
 		}
 	}
 	request.Header.Add("Authorization", c.Token)
-	response, err := client.Do(request)
+	response, err := c.invoke(methodPath, request, client.Do)
 	if err != nil {
-		utils.PrintErrorLog("请求发送失败,error:%+v", err)
+		c.log().Error("请求发送失败", "endpoint", methodPath, "error", err)
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrRequestFailed,
-			Message: fmt.Sprintf("请求发送失败,error:%+v", err),
+			Message: "请求发送失败",
+			Err:     err,
 		}
 	}
 	defer func() {
@@ -161,10 +171,11 @@ func (c *ApiClient) Request(methodPath string, params map[string]string) (*Resul
 	}()
 	resp, err := io.ReadAll(response.Body)
 	if err != nil {
-		utils.PrintErrorLog("请求结果解析失败,error:%+v", err)
+		c.log().Error("请求结果解析失败", "endpoint", methodPath, "error", err)
 		return nil, &utils.WeatherError{
 			Code:    utils.ErrRequestFailed,
-			Message: fmt.Sprintf("请求结果解析失败,error:%+v", err),
+			Message: "请求结果解析失败",
+			Err:     err,
 		}
 	}
 	return &ResultQWeather{
@@ -172,6 +183,19 @@ func (c *ApiClient) Request(methodPath string, params map[string]string) (*Resul
 	}, nil
 }
 
+// SetLogger 设置ApiClient日志，传入 nil 时使用全局日志
+func (c *ApiClient) SetLogger(l utils.Logger) {
+	c.logger = l
+}
+
+// log 获取ApiClient日志
+func (c *ApiClient) log() utils.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return utils.GetLogger()
+}
+
 // sign 签名
 func (c *ApiClient) sign() {
 	c.SPayload.Iat = time.Now().Add(time.Minute * -1).Unix()
@@ -186,71 +210,7 @@ func (c *ApiClient) sign() {
 	c.Token = fmt.Sprintf("Bearer %s.%s.%s", HeaderBase64URL, PayloadBase64URL, SignatureBase64URL)
 }
 
+// GetWeatherIconCode 中文天气状况对应的和风天气图标代码，晴、多云等取日间图标，阵雨、阵雪等沿用 350、457 等代码
 func (c *ApiClient) GetWeatherIconCode() map[string]string {
-	return map[string]string{
-		"晴":        "100",
-		"多云":       "101",
-		"少云":       "102",
-		"晴间多云":     "103",
-		"阴":        "104",
-		"雷阵雨":      "302",
-		"强雷阵雨":     "303",
-		"雷阵雨伴有冰雹":  "304",
-		"小雨":       "305",
-		"中雨":       "306",
-		"大雨":       "307",
-		"极端降雨":     "308",
-		"毛毛雨/细雨":   "309",
-		"暴雨":       "310",
-		"大暴雨":      "311",
-		"特大暴雨":     "312",
-		"冻雨":       "313",
-		"小到中雨":     "314",
-		"中到大雨":     "315",
-		"大到暴雨":     "316",
-		"暴雨到大暴雨":   "317",
-		"大暴雨到特大暴雨": "318",
-		"阵雨":       "350",
-		"强阵雨":      "351",
-		"雨":        "399",
-		"小雪":       "400",
-		"中雪":       "401",
-		"大雪":       "402",
-		"暴雪":       "403",
-		"雨夹雪":      "404",
-		"雨雪天气":     "405",
-		"小到中雪":     "408",
-		"中到大雪":     "409",
-		"大到暴雪":     "410",
-		"阵雨夹雪":     "456",
-		"阵雪":       "457",
-		"雪":        "499",
-		"薄雾":       "500",
-		"雾":        "501",
-		"霾":        "502",
-		"扬沙":       "503",
-		"浮尘":       "504",
-		"沙尘暴":      "507",
-		"强沙尘暴":     "508",
-		"浓雾":       "509",
-		"强浓雾":      "510",
-		"中度霾":      "511",
-		"重度霾":      "512",
-		"严重霾":      "513",
-		"大雾":       "514",
-		"特强浓雾":     "515",
-		"热":        "900",
-		"冷":        "901",
-		"未知":       "999",
-
-		//和风天气夜间天气ICON
-		//"晴":"150",
-		//"多云":"151",
-		//"少云":"152",
-		//"晴间多云":"153",
-		//"阵雨":"300",
-		//"强阵雨":"301",
-		//"阵雨夹雪":"406",
-		//"阵雪":"407",
-	}
+	return weatherIconCodes()
 }
@@ -1,12 +1,28 @@
 package utils
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode 错误码，同时可作为哨兵错误配合 errors.Is 使用
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
 
 // WeatherError 自定义天气分析错误类型
 type WeatherError struct {
-	Code    string
+	Code    ErrorCode
 	Message string
-	Err     error
+	// Field 出错字段名，如 Temperature
+	Field string
+	// Value 出错字段的值
+	Value any
+	// Index 出错记录序号，从1开始，0表示不涉及具体记录
+	Index int
+	Err   error
 }
 
 func (e *WeatherError) Error() string {
@@ -16,15 +32,50 @@ func (e *WeatherError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
-// 预定义错误码
+// Unwrap 返回被包装的底层错误
+func (e *WeatherError) Unwrap() error {
+	return e.Err
+}
+
+// Is 按错误码匹配，target 可以是 ErrorCode 或 *WeatherError
+func (e *WeatherError) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.Code == t
+	case *WeatherError:
+		return t != nil && t.Code != "" && e.Code == t.Code
+	}
+	return false
+}
+
+// CodeOf 获取错误链中第一个 WeatherError 的错误码，不存在时返回空字符串
+func CodeOf(err error) ErrorCode {
+	var we *WeatherError
+	if errors.As(err, &we) {
+		return we.Code
+	}
+	return ""
+}
+
+// 预定义错误码，可直接用于 errors.Is(err, utils.ErrEmptyData)
 const (
-	ErrInvalidInput         = "INVALID_INPUT"         // 输入无效
-	ErrEmptyData            = "EMPTY_DATA"            // 数据为空
-	ErrInvalidTemperature   = "INVALID_TEMPERATURE"   // 温度无效
-	ErrInvalidHumidity      = "INVALID_HUMIDITY"      // 湿度无效
-	ErrInvalidWindSpeed     = "INVALID_WIND_SPEED"    // 风速无效
-	ErrInvalidPrecipitation = "INVALID_PRECIPITATION" // 降雨量无效
-	ErrReadFile             = "READ_FILE_ERROR"       // 读取文件错误
-	ErrPrivateKeyInvalid    = "PRIVATE_KEY_INVALID"   // 私钥无效
-	ErrRequestFailed        = "REQUEST_FAILED"        // 请求失败
+	ErrInvalidInput         ErrorCode = "INVALID_INPUT"          // 输入无效
+	ErrEmptyData            ErrorCode = "EMPTY_DATA"             // 数据为空
+	ErrInvalidTemperature   ErrorCode = "INVALID_TEMPERATURE"    // 温度无效
+	ErrInvalidHumidity      ErrorCode = "INVALID_HUMIDITY"       // 湿度无效
+	ErrInvalidWindSpeed     ErrorCode = "INVALID_WIND_SPEED"     // 风速无效
+	ErrInvalidPrecipitation ErrorCode = "INVALID_PRECIPITATION"  // 降雨量无效
+	ErrReadFile             ErrorCode = "READ_FILE_ERROR"        // 读取文件错误
+	ErrPrivateKeyInvalid    ErrorCode = "PRIVATE_KEY_INVALID"    // 私钥无效
+	ErrRequestFailed        ErrorCode = "REQUEST_FAILED"         // 请求失败
+	ErrMissingTime          ErrorCode = "MISSING_TIME"           // 缺少观测时间
+	ErrInvalidTime          ErrorCode = "INVALID_TIME"           // 观测时间无效
+	ErrMissingCondition     ErrorCode = "MISSING_CONDITION"      // 缺少天气状况
+	ErrImplausibleData      ErrorCode = "IMPLAUSIBLE_DATA"       // 数据不合理
+	ErrTemperatureJump      ErrorCode = "TEMPERATURE_JUMP"       // 温度突变
+	ErrIntensityMismatch    ErrorCode = "INTENSITY_MISMATCH"     // 天气状况与实测降水强度不符
+	ErrInvalidWindDirection ErrorCode = "INVALID_WIND_DIRECTION" // 风向无效
+	ErrConditionMismatch    ErrorCode = "CONDITION_MISMATCH"     // 天气状况与观测数值不符
+	ErrCanceled             ErrorCode = "CANCELED"               // 分析已取消
+	ErrInvalidConfig        ErrorCode = "INVALID_CONFIG"         // 配置无效
 )
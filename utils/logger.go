@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Logger 日志接口，参数 args 为结构化字段的键值对，*slog.Logger 可直接作为实现
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+var (
+	loggerMu     sync.RWMutex
+	globalLogger Logger
+)
+
+// SetLogger 设置全局日志，传入 nil 时恢复为 slog.Default()
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	globalLogger = l
+}
+
+// GetLogger 获取全局日志，未设置时返回 slog.Default()
+func GetLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	if globalLogger == nil {
+		return slog.Default()
+	}
+	return globalLogger
+}
+
+// NopLogger 丢弃全部日志，适用于嵌入本库且不希望输出日志的场景
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
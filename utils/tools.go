@@ -9,24 +9,30 @@ import (
 func Struct2Base64URL(s interface{}) string {
 	str, err := json.Marshal(s)
 	if err != nil {
-		PrintErrorLog(err.Error())
+		GetLogger().Error("JSON序列化失败", "error", err)
 		return ""
 	}
 	b64 := base64.RawURLEncoding.EncodeToString([]byte(string(str)))
 	return b64
 }
 
+// PrintErrorLog 输出错误日志
+//
+// Deprecated: 使用 GetLogger().Error 输出结构化日志
 func PrintErrorLog(msg string, args ...interface{}) {
-	myText := fmt.Sprintf(msg, args...)
-	fmt.Printf("\033[31m[--weather_analyzer--]Error:'%s'\033[0m\n", myText)
+	GetLogger().Error(fmt.Sprintf(msg, args...))
 }
 
+// PrintWarnLog 输出警告日志
+//
+// Deprecated: 使用 GetLogger().Warn 输出结构化日志
 func PrintWarnLog(msg string, args ...interface{}) {
-	myText := fmt.Sprintf(msg, args...)
-	fmt.Printf("\033[33m[--weather_analyzer--]Warning:'%s'\033[0m\n", myText)
+	GetLogger().Warn(fmt.Sprintf(msg, args...))
 }
 
+// PrintInfoLog 输出信息日志
+//
+// Deprecated: 使用 GetLogger().Info 输出结构化日志
 func PrintInfoLog(msg string, args ...interface{}) {
-	myText := fmt.Sprintf(msg, args...)
-	fmt.Printf("\033[34m[--weather_analyzer--]Info:'%s'\033[0m\n", myText)
+	GetLogger().Info(fmt.Sprintf(msg, args...))
 }